@@ -0,0 +1,450 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aymerick/raymond"
+)
+
+var (
+	rxBlockOpen  = regexp.MustCompile(`^\s*\{\{\s*#\s*(\w+)\s+([^{}]+?)\s*\}\}\s*$`)
+	rxBlockElse  = regexp.MustCompile(`^\s*\{\{\s*else\s*\}\}\s*$`)
+	rxBlockClose = regexp.MustCompile(`^\s*\{\{\s*/\s*(\w+)\s*\}\}\s*$`)
+)
+
+// Виды маркеров блока
+const (
+	blockOpen = iota
+	blockElse
+	blockClose
+)
+
+// blockMarker - маркер блока, занимающий весь параграф, строку или ячейку таблицы:
+// {{#each Items}}, {{#if Cond}}, {{else}}, {{/each}}
+type blockMarker struct {
+	kind   int
+	helper string
+	expr   string
+}
+
+// blockHelpers - блоки, которые раскрываются по элементам документа
+var blockHelpers = map[string]bool{
+	"each":   true,
+	"if":     true,
+	"unless": true,
+}
+
+// paragraphMarker - маркер блока в параграфе
+func paragraphMarker(item DocItem) (*blockMarker, bool) {
+	p, ok := item.(*ParagraphItem)
+	if !ok {
+		return nil, false
+	}
+	return textMarker(p.PlainText())
+}
+
+// textMarker - маркер блока в тексте
+func textMarker(text string) (*blockMarker, bool) {
+	if match := rxBlockOpen.FindStringSubmatch(text); match != nil {
+		if blockHelpers[match[1]] {
+			return &blockMarker{kind: blockOpen, helper: match[1], expr: match[2]}, true
+		}
+		return nil, false
+	}
+	if rxBlockElse.MatchString(text) {
+		return &blockMarker{kind: blockElse, helper: "else"}, true
+	}
+	if match := rxBlockClose.FindStringSubmatch(text); match != nil {
+		if blockHelpers[match[1]] {
+			return &blockMarker{kind: blockClose, helper: match[1]}, true
+		}
+	}
+	return nil, false
+}
+
+// findBlockEnd - поиск {{else}} и закрывающего маркера блока, открытого в start
+// markers - маркеры элементов по индексу
+func findBlockEnd(markers []*blockMarker, start int) (elseIndex int, end int, err error) {
+	elseIndex = -1
+	depth := 0
+	for index := start; index < len(markers); index++ {
+		marker := markers[index]
+		if marker == nil {
+			continue
+		}
+		switch marker.kind {
+		case blockOpen:
+			depth++
+		case blockElse:
+			if depth == 1 && elseIndex < 0 {
+				elseIndex = index
+			}
+		case blockClose:
+			depth--
+			if depth == 0 {
+				if marker.helper != markers[start].helper {
+					return -1, -1, errors.New("Not valid close of block " + markers[start].helper + ": " + marker.helper)
+				}
+				return elseIndex, index, nil
+			}
+		}
+	}
+	return -1, -1, errors.New("Not closed block " + markers[start].helper + " " + markers[start].expr)
+}
+
+// blockSegment - участок списка: обычный элемент (marker == nil) или блок верхнего уровня
+type blockSegment struct {
+	index  int
+	marker *blockMarker
+	// body, alt - границы [from, to) основной ветки и ветки {{else}}
+	body, alt [2]int
+}
+
+// splitBlocks - разбиение списка по маркерам на обычные элементы и блоки
+func splitBlocks(markers []*blockMarker) ([]blockSegment, error) {
+	result := make([]blockSegment, 0, len(markers))
+	for index := 0; index < len(markers); index++ {
+		marker := markers[index]
+		if marker == nil {
+			result = append(result, blockSegment{index: index})
+			continue
+		}
+		if marker.kind != blockOpen {
+			return nil, errors.New("Not opened block " + marker.helper)
+		}
+		elseIndex, end, err := findBlockEnd(markers, index)
+		if err != nil {
+			return nil, err
+		}
+		segment := blockSegment{index: index, marker: marker, body: [2]int{index + 1, end}, alt: [2]int{end, end}}
+		if elseIndex >= 0 {
+			segment.body[1] = elseIndex
+			segment.alt = [2]int{elseIndex + 1, end}
+		}
+		result = append(result, segment)
+		index = end
+	}
+	return result, nil
+}
+
+// blockContexts - данные для повторений блока и выбранная ветка
+// each - элементы массива (ветка else с v, если массив пуст), if/unless - одно повторение с v
+func (r *renderer) blockContexts(marker *blockMarker, v interface{}) (contexts []interface{}, useAlt bool, err error) {
+	value, err := r.evalExpression(v, marker.expr)
+	if err != nil {
+		return nil, false, err
+	}
+	switch marker.helper {
+	case "each":
+		elements := blockElements(value)
+		if len(elements) == 0 {
+			return []interface{}{v}, true, nil
+		}
+		return elements, false, nil
+	case "if":
+		return []interface{}{v}, !raymond.IsTrue(value), nil
+	case "unless":
+		return []interface{}{v}, raymond.IsTrue(value), nil
+	}
+	return nil, false, errors.New("Unknown block " + marker.helper)
+}
+
+// renderItems - рендер списка элементов с раскрытием блоков
+// Параграфы-маркеры блоков удаляются, содержимое блока повторяется или выбрасывается
+func (r *renderer) renderItems(items []DocItem, v interface{}) ([]DocItem, error) {
+	markers := make([]*blockMarker, len(items))
+	for index, item := range items {
+		if p, ok := item.(*ParagraphItem); ok {
+			findTemplatePatternsInParagraph(p)
+		}
+		if marker, ok := paragraphMarker(item); ok {
+			markers[index] = marker
+		}
+	}
+	segments, err := splitBlocks(markers)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]DocItem, 0, len(items))
+	for _, segment := range segments {
+		if segment.marker == nil {
+			item := items[segment.index]
+			if err := r.renderDocItem(item, v); err != nil {
+				return nil, err
+			}
+			// Таблица, из которой удалены все строки, удаляется
+			if table, ok := item.(*TableItem); ok && len(table.Rows) == 0 {
+				continue
+			}
+			result = append(result, item)
+			continue
+		}
+		contexts, useAlt, err := r.blockContexts(segment.marker, v)
+		if err != nil {
+			return nil, err
+		}
+		bounds := segment.body
+		if useAlt {
+			bounds = segment.alt
+		}
+		for _, context := range contexts {
+			branch := items[bounds[0]:bounds[1]]
+			// Ветка, которая выводится один раз, используется без копирования
+			if len(contexts) > 1 {
+				branch = cloneItems(branch)
+			}
+			out, err := r.renderItems(branch, context)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, out...)
+		}
+	}
+	return result, nil
+}
+
+/* БЛОКИ В ТАБЛИЦАХ */
+
+// rowMarker - маркер блока в строке таблицы: маркер в одной ячейке, остальные ячейки пустые
+func rowMarker(row *TableRow) (*blockMarker, bool) {
+	var marker *blockMarker
+	if row == nil {
+		return nil, false
+	}
+	for _, cell := range row.Cells {
+		text := strings.TrimSpace(plainTextFromTableCell(cell))
+		if len(text) == 0 {
+			continue
+		}
+		if marker != nil {
+			return nil, false
+		}
+		m, ok := textMarker(text)
+		if !ok {
+			return nil, false
+		}
+		marker = m
+	}
+	return marker, marker != nil
+}
+
+// renderRowBlocks - рендер строк таблицы с раскрытием блоков
+// Строки-маркеры удаляются, строки блока повторяются или выбрасываются
+func (r *renderer) renderRowBlocks(rows []*TableRow, markers []*blockMarker, v interface{}) ([]*TableRow, error) {
+	segments, err := splitBlocks(markers)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*TableRow, 0, len(rows))
+	// Подряд идущие обычные строки выводятся вместе
+	pending := make([]*TableRow, 0)
+	flush := func() error {
+		if len(pending) > 0 {
+			out, err := r.renderRows(pending, v)
+			if err != nil {
+				return err
+			}
+			result = append(result, out...)
+			pending = make([]*TableRow, 0)
+		}
+		return nil
+	}
+	for _, segment := range segments {
+		if segment.marker == nil {
+			pending = append(pending, rows[segment.index])
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		contexts, useAlt, err := r.blockContexts(segment.marker, v)
+		if err != nil {
+			return nil, err
+		}
+		bounds := segment.body
+		if useAlt {
+			bounds = segment.alt
+		}
+		for _, context := range contexts {
+			branch := rows[bounds[0]:bounds[1]]
+			if len(contexts) > 1 {
+				branch = cloneRows(branch)
+			}
+			out, err := r.renderRows(branch, context)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, out...)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cellMarker - маркер блока, занимающий всю ячейку
+func cellMarker(cell *TableCell) (*blockMarker, bool) {
+	if cell == nil {
+		return nil, false
+	}
+	return textMarker(strings.TrimSpace(plainTextFromTableCell(cell)))
+}
+
+// renderCellBlocks - рендер ячеек строки с условными блоками
+// Ячейки не удаляются, чтобы не нарушать сетку таблицы: ячейки-маркеры и ячейки
+// невыбранной ветки очищаются
+func (r *renderer) renderCellBlocks(cells []*TableCell, markers []*blockMarker, v interface{}) error {
+	segments, err := splitBlocks(markers)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if segment.marker == nil {
+			if err := r.renderCells(cells[segment.index:segment.index+1], v); err != nil {
+				return err
+			}
+			continue
+		}
+		if segment.marker.helper == "each" {
+			return errors.New("Not supported block each in table cells")
+		}
+		contexts, useAlt, err := r.blockContexts(segment.marker, v)
+		if err != nil {
+			return err
+		}
+		taken, skipped := segment.body, segment.alt
+		if useAlt {
+			taken, skipped = segment.alt, segment.body
+		}
+		if err := r.renderCells(cells[taken[0]:taken[1]], contexts[0]); err != nil {
+			return err
+		}
+		for _, cell := range cells[skipped[0]:skipped[1]] {
+			clearCell(cell)
+		}
+		// Ячейки-маркеры
+		clearCell(cells[segment.index])
+		if segment.body[1] < segment.alt[0] {
+			clearCell(cells[segment.body[1]])
+		}
+		clearCell(cells[segment.alt[1]])
+	}
+	return nil
+}
+
+// clearCell - очистка ячейки, остается один пустой параграф с параметрами первого параграфа
+func clearCell(cell *TableCell) {
+	if cell == nil {
+		return
+	}
+	p := new(ParagraphItem)
+	for _, item := range cell.Items {
+		if paragraph, ok := item.(*ParagraphItem); ok {
+			p.Params = paragraph.Params
+			break
+		}
+	}
+	cell.Items = []DocItem{p}
+}
+
+// cloneRows - копия строк блока
+func cloneRows(rows []*TableRow) []*TableRow {
+	result := make([]*TableRow, 0, len(rows))
+	for _, row := range rows {
+		if row != nil {
+			result = append(result, row.Clone())
+		}
+	}
+	return result
+}
+
+// cloneItems - копия элементов блока
+func cloneItems(items []DocItem) []DocItem {
+	result := make([]DocItem, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			result = append(result, item.Clone())
+		}
+	}
+	return result
+}
+
+// lookupValue - значение по пути ("Items", "Order.Items", "Order$Items", "this")
+// Для строки таблицы, разложенной из массива, путь считается от элемента массива
+func lookupValue(v interface{}, path string) (interface{}, bool) {
+	path = strings.TrimSpace(path)
+	if path == "this" || path == "." {
+		return v, true
+	}
+	names := strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '$' })
+	if line, ok := v.(*map[string]interface{}); ok {
+		if len(names) > 1 {
+			names = names[1:]
+		}
+		value, ok := (*line)[strings.Join(names, "_")]
+		return value, ok
+	}
+	val := reflect.ValueOf(v)
+	for _, name := range names {
+		for val.IsValid() && (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) {
+			val = val.Elem()
+		}
+		if !val.IsValid() {
+			return nil, false
+		}
+		switch val.Kind() {
+		case reflect.Struct:
+			val = val.FieldByName(name)
+		case reflect.Map:
+			if val.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			val = val.MapIndex(reflect.ValueOf(name).Convert(val.Type().Key()))
+		default:
+			return nil, false
+		}
+		if !val.IsValid() {
+			return nil, false
+		}
+	}
+	if !val.IsValid() || !val.CanInterface() {
+		return nil, false
+	}
+	return val.Interface(), true
+}
+
+// blockElements - элементы массива или значения map (по порядку ключей)
+func blockElements(v interface{}) []interface{} {
+	val := reflect.ValueOf(v)
+	for val.IsValid() && (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) {
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil
+	}
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice:
+		result := make([]interface{}, 0, val.Len())
+		for index := 0; index < val.Len(); index++ {
+			result = append(result, val.Index(index).Interface())
+		}
+		return result
+	case reflect.Map:
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		result := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			result = append(result, val.MapIndex(key).Interface())
+		}
+		return result
+	}
+	return nil
+}
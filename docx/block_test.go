@@ -0,0 +1,106 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+// testParagraphs - параграфы по одному на строку текста
+func testParagraphs(lines ...string) string {
+	result := ""
+	for _, line := range lines {
+		result += testParagraph(line)
+	}
+	return result
+}
+
+var testBlockData = map[string]interface{}{
+	"Show": true,
+	"Hide": false,
+	"Tags": []string{"a", "b"},
+	"None": []string{},
+	"Items": []map[string]interface{}{
+		{"Name": "x", "Paid": true},
+		{"Name": "y", "Paid": false},
+	},
+}
+
+func TestEachBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"inline", testParagraph("{{#each Tags}}{{this}},{{/each}}"), "a,b,"},
+		{"inline with text", testParagraph("Tags: {{#each Tags}}[{{this}}]{{/each}}."), "Tags: [a][b]."},
+		{"paragraphs", testParagraphs("{{#each Items}}", "Name {{Name}}", "{{/each}}", "end"), "Name x\nName y\nend"},
+		{"paragraphs with else", testParagraphs("{{#each None}}", "{{this}}", "{{else}}", "empty", "{{/each}}"), "empty"},
+		{"nested if", testParagraphs("{{#each Items}}", "{{#if Paid}}", "paid {{Name}}", "{{/if}}", "{{/each}}"), "paid x"},
+		{"table", testParagraphs("{{#each Tags}}") + testTable([]string{"{{this}}"}) + testParagraphs("{{/each}}"), "a\nb"},
+		{"table rows", testTable([]string{"{{#each Items}}"}, []string{"{{Name}}"}, []string{"{{/each}}"}), "x\ny"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, test.body, testBlockData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestConditionalBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"inline if", testParagraph("{{#if Show}}yes{{/if}}"), "yes"},
+		{"inline if false", testParagraph("a{{#if Hide}}yes{{/if}}b"), "ab"},
+		{"inline if else", testParagraph("{{#if Hide}}yes{{else}}no{{/if}}"), "no"},
+		{"inline unless", testParagraph("{{#unless Hide}}shown{{/unless}}"), "shown"},
+		{"inline unless else", testParagraph("{{#unless Show}}shown{{else}}hidden{{/unless}}"), "hidden"},
+		{"block if", testParagraphs("{{#if Show}}", "yes", "{{/if}}", "end"), "yes\nend"},
+		{"block if false", testParagraphs("{{#if Hide}}", "yes", "{{/if}}", "end"), "end"},
+		{"block if else", testParagraphs("{{#if Hide}}", "yes", "{{else}}", "no", "{{/if}}"), "no"},
+		{"block unless", testParagraphs("{{#unless Hide}}", "shown", "{{/unless}}"), "shown"},
+		{"block unless else", testParagraphs("{{#unless Show}}", "shown", "{{else}}", "hidden", "{{/unless}}"), "hidden"},
+		{"block if table", testParagraphs("{{#if Hide}}") + testTable([]string{"cell"}) + testParagraphs("{{/if}}", "end"), "end"},
+		{"rows", testTable([]string{"head"}, []string{"{{#if Hide}}"}, []string{"row"}, []string{"{{else}}"}, []string{"other"}, []string{"{{/if}}"}), "head\nother"},
+		{"marker with spaces", testParagraphs("  {{ #if Show }}  ", "yes", "{{ /if }}"), "yes"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, test.body, testBlockData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestBlockErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"not closed", testParagraphs("{{#if Show}}", "yes"), "Not closed block"},
+		{"not opened", testParagraphs("yes", "{{/if}}"), "Not opened block"},
+		{"wrong close", testParagraphs("{{#if Show}}", "yes", "{{/each}}"), "Not valid close of block"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := testRender(t, test.body, testBlockData)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
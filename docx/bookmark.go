@@ -5,8 +5,10 @@ import (
 )
 
 type IBookMark struct {
-	ID   string `xml:"id,attr,omitempty"`
-	Name string `xml:"name,attr,omitempty"`
+	ID       string `xml:"id,attr,omitempty"`
+	Name     string `xml:"name,attr,omitempty"`
+	ColFirst string `xml:"colFirst,attr,omitempty"`
+	ColLast  string `xml:"colLast,attr,omitempty"`
 }
 
 type BookMarkStart struct {
@@ -16,40 +18,74 @@ type BookMarkEnd struct {
 	IBookMark
 }
 
+// decodeBookMark - атрибуты закладки
+func decodeBookMark(element *xml.StartElement) IBookMark {
+	var b IBookMark
+	for _, attr := range element.Attr {
+		switch attr.Name.Local {
+		case "id":
+			b.ID = attr.Value
+		case "name":
+			b.Name = attr.Value
+		case "colFirst":
+			b.ColFirst = attr.Value
+		case "colLast":
+			b.ColLast = attr.Value
+		}
+	}
+	return b
+}
+
 func (b *IBookMark) Tag() string {
 	return "bookMark"
 }
 func (b *BookMarkEnd) Tag() string {
-	return "bookMarkEnd"
+	return "bookmarkEnd"
 }
 func (b *BookMarkEnd) Clone() DocItem {
 	result := new(BookMarkEnd)
+	result.IBookMark = b.IBookMark
 	return result
 }
 func (b *BookMarkStart) Tag() string {
-	return "bookMarkStart"
+	return "bookmarkStart"
 }
 func (b *BookMarkStart) Clone() DocItem {
 	result := new(BookMarkStart)
+	result.IBookMark = b.IBookMark
 	return result
 }
 func (b *IBookMark) Type() DocItemType {
 	return BookMark
 }
+
+// PlainText - у закладки нет текста
 func (b *IBookMark) PlainText() string {
-	return b.Name
+	return ""
 }
 func (b *IBookMark) decode(decoder *xml.Decoder) error {
-	return nil
+	return decoder.Skip()
 }
-func (b *BookMarkStart) encode(encoder *xml.Encoder) error {
+
+// attrs - атрибуты закладки для кодирования
+func (b *IBookMark) attrs() []xml.Attr {
 	var attrs []xml.Attr
-	attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "w:" + "id"}, Value: b.ID})
+	attrs = append(attrs, xml.Attr{Name: xml.Name{Space: nsW, Local: "id"}, Value: b.ID})
 	if b.Name != "" {
-		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "w:" + "name"}, Value: b.Name})
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: nsW, Local: "name"}, Value: b.Name})
+	}
+	if b.ColFirst != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: nsW, Local: "colFirst"}, Value: b.ColFirst})
 	}
-	start := xml.StartElement{Name: xml.Name{Local: "w:" + b.Tag()},
-		Attr: attrs}
+	if b.ColLast != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: nsW, Local: "colLast"}, Value: b.ColLast})
+	}
+	return attrs
+}
+
+func (b *BookMarkStart) encode(encoder *xmlEncoder) error {
+	start := xml.StartElement{Name: xml.Name{Space: nsW, Local: b.Tag()},
+		Attr: b.attrs()}
 	if err := encoder.EncodeToken(start); err != nil {
 		return err
 	}
@@ -60,14 +96,9 @@ func (b *BookMarkStart) encode(encoder *xml.Encoder) error {
 	return encoder.Flush()
 }
 
-func (b *BookMarkEnd) encode(encoder *xml.Encoder) error {
-	var attrs []xml.Attr
-	attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "w:" + "id"}, Value: b.ID})
-	if b.Name != "" {
-		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "w:" + "name"}, Value: b.Name})
-	}
-	start := xml.StartElement{Name: xml.Name{Local: "w:" + b.Tag()},
-		Attr: attrs}
+func (b *BookMarkEnd) encode(encoder *xmlEncoder) error {
+	start := xml.StartElement{Name: xml.Name{Space: nsW, Local: b.Tag()},
+		Attr: b.attrs()}
 	if err := encoder.EncodeToken(start); err != nil {
 		return err
 	}
@@ -0,0 +1,217 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aymerick/raymond"
+)
+
+// expandColumns - горизонтальное раскрытие столбцов, отмеченных флагом [h-expand]
+// Столбец повторяется во всех строках таблицы по элементам массива из шаблона отмеченной ячейки
+func expandColumns(table *TableItem, v interface{}) error {
+	for _, row := range table.Rows {
+		if row == nil {
+			continue
+		}
+		for cellIndex := 0; cellIndex < len(row.Cells); cellIndex++ {
+			cell := row.Cells[cellIndex]
+			for _, item := range cell.Items {
+				if p, ok := item.(*ParagraphItem); ok {
+					findTemplatePatternsInParagraph(p)
+				}
+			}
+			text := plainTextFromTableCell(cell)
+			if !rxExpandCellH.MatchString(text) {
+				continue
+			}
+			removeTemplateFromCell(rxExpandCellH, cell)
+			match := rxTemplateItem.FindStringSubmatch(text)
+			if match == nil {
+				return errors.New("Not found array for [h-expand]")
+			}
+			name := strings.Split(match[1], "$")[0]
+			value, _ := lookupValue(v, name)
+			elements := blockElements(value)
+			expandColumn(table, gridColumn(row, cellIndex), len(elements), func(clone *TableCell, index int) {
+				renderColumnCell(clone, name, elements[index])
+			})
+			cellIndex += len(elements) - 1
+		}
+	}
+	return nil
+}
+
+// cellSpan - количество столбцов сетки, занимаемых ячейкой
+func cellSpan(cell *TableCell) int {
+	if cell != nil && cell.Params.GridSpan != nil && cell.Params.GridSpan.Value > 1 {
+		return int(cell.Params.GridSpan.Value)
+	}
+	return 1
+}
+
+// gridColumn - столбец сетки, с которого начинается ячейка
+func gridColumn(row *TableRow, cellIndex int) int {
+	column := 0
+	for _, cell := range row.Cells[:cellIndex] {
+		column += cellSpan(cell)
+	}
+	return column
+}
+
+// cellAtColumn - ячейка строки, которая начинается со столбца сетки column
+func cellAtColumn(row *TableRow, column int) *TableCell {
+	position := 0
+	for _, cell := range row.Cells {
+		if position == column {
+			return cell
+		}
+		position += cellSpan(cell)
+	}
+	return nil
+}
+
+// expandColumn - замена столбца сетки column на count столбцов
+// Ячейки столбца клонируются (fill заполняет клон), ячейки, объединенные через столбец,
+// расширяются через gridSpan. Ширина столбца делится между новыми столбцами
+func expandColumn(table *TableItem, column int, count int, fill func(cell *TableCell, index int)) {
+	// Сетка
+	if column < len(table.Grid.Cols) && table.Grid.Cols[column] != nil {
+		width := *table.Grid.Cols[column]
+		cols := make([]*WidthValue, 0, len(table.Grid.Cols)+count)
+		cols = append(cols, table.Grid.Cols[:column]...)
+		for _, part := range splitWidth(width.Value, count) {
+			cols = append(cols, &WidthValue{Value: part, Type: width.Type})
+		}
+		cols = append(cols, table.Grid.Cols[column+1:]...)
+		// Ширина удаленного столбца переходит соседнему, чтобы таблица не сужалась
+		if count == 0 && len(cols) > 0 {
+			neighbor := column - 1
+			if neighbor < 0 {
+				neighbor = 0
+			}
+			cols[neighbor] = &WidthValue{Value: cols[neighbor].Value + width.Value, Type: cols[neighbor].Type}
+		}
+		table.Grid.Cols = cols
+	}
+	// Строки
+	for _, row := range table.Rows {
+		if row == nil {
+			continue
+		}
+		position := 0
+		for cellIndex, cell := range row.Cells {
+			span := cellSpan(cell)
+			if position == column && span == 1 {
+				clones := make([]*TableCell, 0, count)
+				for index, part := range splitWidth(cellWidth(cell), count) {
+					clone := cell.Clone()
+					if clone.Params.Width != nil {
+						clone.Params.Width.Value = part
+					}
+					fill(clone, index)
+					clones = append(clones, clone)
+				}
+				if count == 0 {
+					widenNeighbor(row, cellIndex, cellWidth(cell))
+				}
+				row.Cells = append(row.Cells[:cellIndex], append(clones, row.Cells[cellIndex+1:]...)...)
+				break
+			}
+			if position <= column && column < position+span {
+				// Ячейка объединена через столбец
+				if cell.Params.GridSpan == nil {
+					cell.Params.GridSpan = new(IntValue)
+				}
+				cell.Params.GridSpan.Value = int64(span + count - 1)
+				if cell.Params.GridSpan.Value <= 1 {
+					cell.Params.GridSpan = nil
+				}
+				break
+			}
+			position += span
+		}
+	}
+}
+
+// mergeCellRight - объединение ячейки с соседней справа через gridSpan
+// Содержимое соседней ячейки удаляется, ширина добавляется к ячейке
+func mergeCellRight(row *TableRow, cellIndex int) {
+	cell, right := row.Cells[cellIndex], row.Cells[cellIndex+1]
+	cell.Params.GridSpan = &IntValue{Value: int64(cellSpan(cell) + cellSpan(right))}
+	if cell.Params.Width != nil && right.Params.Width != nil && cell.Params.Width.Type == right.Params.Width.Type {
+		cell.Params.Width.Value += right.Params.Width.Value
+	}
+	row.Cells = append(row.Cells[:cellIndex+1], row.Cells[cellIndex+2:]...)
+}
+
+// splitWidth - деление ширины на count частей, остаток достается последней
+func splitWidth(width int64, count int) []int64 {
+	result := make([]int64, count)
+	for index := range result {
+		result[index] = width / int64(count)
+	}
+	if count > 0 {
+		result[count-1] += width % int64(count)
+	}
+	return result
+}
+
+// cellWidth - ширина ячейки (tcW)
+func cellWidth(cell *TableCell) int64 {
+	if cell.Params.Width != nil {
+		return cell.Params.Width.Value
+	}
+	return 0
+}
+
+// widenNeighbor - передача ширины удаляемой ячейки соседней
+func widenNeighbor(row *TableRow, cellIndex int, width int64) {
+	neighbor := cellIndex - 1
+	if neighbor < 0 {
+		neighbor = cellIndex + 1
+	}
+	if neighbor < len(row.Cells) && row.Cells[neighbor].Params.Width != nil {
+		row.Cells[neighbor].Params.Width.Value += width
+	}
+}
+
+// renderColumnCell - подстановка элемента массива в шаблоны {{Name$Field}} ячейки столбца
+// Прочие шаблоны остаются для вывода строки
+func renderColumnCell(cell *TableCell, name string, element interface{}) {
+	for _, item := range cell.Items {
+		renderColumnDocItem(item, name, element)
+	}
+}
+
+func renderColumnDocItem(item DocItem, name string, element interface{}) {
+	switch elem := item.(type) {
+	case *ParagraphItem:
+		{
+			for _, i := range elem.Items {
+				renderColumnDocItem(i, name, element)
+			}
+		}
+	case *ContainerItem:
+		{
+			for _, i := range elem.Items {
+				renderColumnDocItem(i, name, element)
+			}
+		}
+	case *RecordItem:
+		{
+			elem.Text.Value = rxTemplateItem.ReplaceAllStringFunc(elem.Text.Value, func(expr string) string {
+				names := strings.Split(rxTemplateItem.FindStringSubmatch(expr)[1], "$")
+				if names[0] != name {
+					return expr
+				}
+				path := "this"
+				if len(names) > 1 {
+					path = strings.Join(names[1:], "$")
+				}
+				value, _ := lookupValue(element, path)
+				return raymond.Str(value)
+			})
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package docx
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// Template - скомпилированный шаблон
+// Хранит разобранные части документа и сжатое содержимое остальных частей пакета в памяти,
+// при рендере не изменяется и может использоваться из нескольких горутин одновременно
+type Template struct {
+	document  *Document
+	headers   map[string]*Header
+	footers   map[string]*Header
+	notes     map[string]*Header
+	relations *Relationships
+	entries   []packageEntry
+	// deterministic - фиксированное время изменения частей при записи
+	deterministic bool
+	// directives - директивы ячеек таблицы шаблона на момент компиляции
+	directives map[string]Directive
+	// rowHooks, cellHooks - обработчики строк и ячеек на момент компиляции
+	rowHooks  []RowHook
+	cellHooks []CellHook
+	// helpers - помощники шаблона на момент компиляции
+	helpers map[string]interface{}
+}
+
+// Compile (SimpleDocxFile) - компиляция шаблона
+// Шаблонные вставки, разбитые на несколько записей, склеиваются один раз при компиляции
+func (f *SimpleDocxFile) Compile() (*Template, error) {
+	if f.zipFile == nil {
+		return nil, errors.New("Not loaded file")
+	}
+	if f.document == nil {
+		return nil, errors.New("Not valid document")
+	}
+	t := new(Template)
+	t.document = f.document.Clone()
+	t.headers = cloneHeaders(f.headers)
+	t.footers = cloneHeaders(f.footers)
+	t.notes = cloneHeaders(f.notes)
+	t.relations = f.relations
+	t.deterministic = f.deterministic
+	t.directives = make(map[string]Directive, len(f.directives))
+	for name, directive := range f.directives {
+		t.directives[name] = directive
+	}
+	t.rowHooks = append([]RowHook(nil), f.rowHooks...)
+	t.cellHooks = append([]CellHook(nil), f.cellHooks...)
+	t.helpers = make(map[string]interface{}, len(f.helpers))
+	for name, helper := range f.helpers {
+		t.helpers[name] = helper
+	}
+	// Склеиваем шаблонные вставки
+	for _, item := range t.document.Body.Items {
+		findTemplatePatternsInDocItem(item)
+	}
+	for _, parts := range []map[string]*Header{t.headers, t.footers, t.notes} {
+		for _, part := range parts {
+			for _, item := range part.Items {
+				findTemplatePatternsInDocItem(item)
+			}
+		}
+	}
+	// Загружаем остальные части пакета в память
+	t.entries = make([]packageEntry, 0, len(f.zipFile.File))
+	for _, entry := range packageEntries(f.zipFile) {
+		if entry.header.Name != "word/document.xml" && f.part(entry.header.Name) == nil {
+			r, err := entry.raw()
+			if err != nil {
+				return nil, err
+			}
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			entry = memoryEntry(entry.header, data)
+		} else {
+			// Разобранные части кодируются при записи
+			entry.open = nil
+			entry.raw = nil
+		}
+		t.entries = append(t.entries, entry)
+	}
+	return t, nil
+}
+
+// Execute (Template) - рендер копии шаблона и запись результата
+func (t *Template) Execute(v interface{}, writer io.Writer, opts ...RenderOption) error {
+	f := t.instance()
+	if err := f.Render(v, opts...); err != nil {
+		return err
+	}
+	return f.writePackage(writer, t.entries)
+}
+
+// instance - копия разобранных частей шаблона для рендера
+func (t *Template) instance() *SimpleDocxFile {
+	f := new(SimpleDocxFile)
+	f.document = t.document.Clone()
+	f.headers = cloneHeaders(t.headers)
+	f.footers = cloneHeaders(t.footers)
+	f.notes = cloneHeaders(t.notes)
+	f.relations = t.relations
+	f.deterministic = t.deterministic
+	f.directives = t.directives
+	f.rowHooks = t.rowHooks
+	f.cellHooks = t.cellHooks
+	f.helpers = t.helpers
+	return f
+}
+
+func cloneHeaders(headers map[string]*Header) map[string]*Header {
+	result := make(map[string]*Header)
+	for name, header := range headers {
+		if header != nil {
+			result[name] = header.Clone()
+		}
+	}
+	return result
+}
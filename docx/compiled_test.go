@@ -0,0 +1,107 @@
+package docx
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testCompile - скомпилированный шаблон с таблицами, директивами, помощниками и объединением ячеек
+func testCompile(t testing.TB) *Template {
+	t.Helper()
+	body := testParagraph(`{{Title}}: {{upper Title}}, {{sum Orders "Qty"}}`) +
+		testTable([]string{"[h-merge]Orders", "Orders", "[mark]{{count Orders}}"},
+			[]string{"[v-merge]{{Orders$Category}}", "[bold:true]{{Orders$Name}}", "{{price Orders$Price}}"}) +
+		testParagraphs("{{#each Orders}}", "{{Name}} x {{Qty}}", "{{/each}}")
+	f, err := OpenBytes(testPackage(t, body), Deterministic())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RegisterHelper("upper", strings.ToUpper); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RegisterHelper("price", func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	f.RegisterDirective("mark", func(ctx *DirectiveContext) error {
+		ctx.Cell.SetShading("FFFF00")
+		return nil
+	})
+	f.OnRow(func(row *TableRow, item map[string]interface{}, index int) {
+		if index%2 == 1 {
+			row.SetShading("F2F2F2")
+		}
+	})
+	c, err := f.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// testOrdersFor - данные рендера с номером n
+func testOrdersFor(n int) *testOrders {
+	orders := append([]testOrder(nil), testOrdersData.Orders[:n%len(testOrdersData.Orders)+1]...)
+	return &testOrders{Title: "T" + strconv.Itoa(n), Orders: orders}
+}
+
+func TestExecuteConcurrent(t *testing.T) {
+	c := testCompile(t)
+	const count = 16
+	// Результаты последовательного рендера
+	want := make([][]byte, count)
+	for n := range want {
+		buffer := new(bytes.Buffer)
+		if err := c.Execute(testOrdersFor(n), buffer); err != nil {
+			t.Fatal(err)
+		}
+		want[n] = buffer.Bytes()
+	}
+	f, err := OpenBytes(want[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := testText(t, f)
+	for _, part := range []string{"T3: T3, 7", "Orders|4", "A|a1|1.50", "|a2|2.25", "b1 x 3"} {
+		if !strings.Contains(text, part) {
+			t.Errorf("rendered text %q does not contain %q", text, part)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, count*4)
+	for index := 0; index < count*4; index++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			buffer := new(bytes.Buffer)
+			if err := c.Execute(testOrdersFor(n), buffer); err != nil {
+				errs <- err
+				return
+			}
+			if !bytes.Equal(buffer.Bytes(), want[n]) {
+				t.Errorf("concurrent result %d differs from sequential result", n)
+			}
+		}(index % count)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Шаблон не изменился после рендера
+	for n := range want {
+		buffer := new(bytes.Buffer)
+		if err := c.Execute(testOrdersFor(n), buffer); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buffer.Bytes(), want[n]) {
+			t.Errorf("result %d after concurrent runs differs", n)
+		}
+	}
+}
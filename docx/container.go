@@ -0,0 +1,102 @@
+package docx
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+// ContainerItem - элемент-контейнер с вложенными элементами документа
+// (w:footnote, w:endnote, w:comment, w:hyperlink, w:sdt, w:ins, w:fldSimple, ...)
+type ContainerItem struct {
+	Name  string
+	Attrs []xml.Attr
+	Items []DocItem
+}
+
+// Tag - имя тега элемента
+func (item *ContainerItem) Tag() string {
+	return item.Name
+}
+
+// Type - тип элемента
+func (item *ContainerItem) Type() DocItemType {
+	return Container
+}
+
+// PlainText - текст
+func (item *ContainerItem) PlainText() string {
+	var result string
+	for _, i := range item.Items {
+		result += i.PlainText()
+	}
+	return result
+}
+
+// Clone - клонирование
+func (item *ContainerItem) Clone() DocItem {
+	result := new(ContainerItem)
+	result.Name = item.Name
+	result.Attrs = append([]xml.Attr(nil), item.Attrs...)
+	result.Items = make([]DocItem, 0)
+	for _, i := range item.Items {
+		if i != nil {
+			result.Items = append(result.Items, i.Clone())
+		}
+	}
+	return result
+}
+
+// Декодирование контейнера
+func (item *ContainerItem) decode(decoder *xml.Decoder) error {
+	if decoder != nil {
+		var end bool
+		for !end {
+			token, _ := decoder.Token()
+			if token == nil {
+				break
+			}
+			switch element := token.(type) {
+			case xml.StartElement:
+				{
+					i := decodeItem(&element, decoder)
+					if i != nil {
+						item.Items = append(item.Items, i)
+					}
+				}
+			case xml.EndElement:
+				{
+					if element.Name.Local == item.Name {
+						end = true
+					}
+				}
+			}
+		}
+		return nil
+	}
+	return errors.New("Not have decoder")
+}
+
+/* КОДИРОВАНИЕ */
+
+// Кодирование контейнера
+func (item *ContainerItem) encode(encoder *xmlEncoder) error {
+	if encoder != nil {
+		// Начало контейнера
+		start := xml.StartElement{Name: xml.Name{Space: nsW, Local: item.Tag()}, Attr: item.Attrs}
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		// Кодируем составные элементы
+		for _, i := range item.Items {
+			if err := i.encode(encoder); err != nil {
+				return err
+			}
+		}
+		// Конец контейнера
+		if err := encoder.EncodeToken(start.End()); err != nil {
+			return err
+		}
+		return encoder.Flush()
+	}
+	return errors.New("Not have encoder")
+}
@@ -0,0 +1,267 @@
+package docx
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// rxDirective - директива ячейки таблицы: [name] или [name:args]
+var rxDirective = regexp.MustCompile(`\[\s?([\w\-]+)\s?(?::\s?([^\]]*?)\s?)?\]`)
+
+// DirectiveContext - контекст выполнения директивы ячейки таблицы
+type DirectiveContext struct {
+	Table     *TableItem
+	Row       *TableRow
+	Cell      *TableCell
+	RowIndex  int
+	CellIndex int
+	// Raw, Name, Value - директива, ее имя и аргументы ([color:FF0000] - "color", "FF0000")
+	Raw   string
+	Name  string
+	Value string
+	// Args - аргументы, разделенные запятой
+	Args []string
+	// Text - текст ячейки до удаления директив
+	Text string
+	// State - общее состояние директив одной строки
+	State map[string]interface{}
+	// RemoveRow - удалить строку после выполнения директив
+	RemoveRow bool
+}
+
+// Directive - обработчик директивы ячейки таблицы
+// Директивы выполняются после рендера таблицы, строки обходятся снизу вверх, ячейки - слева направо
+type Directive func(ctx *DirectiveContext) error
+
+var (
+	directivesMutex sync.RWMutex
+	// directives - общие директивы всех шаблонов
+	directives = map[string]Directive{
+		"v-merge": directiveVerticalMerge,
+		"index":   directiveNone,
+		"BR":      directiveBoldRight,
+		"bold":    directiveBold,
+		"italic":  directiveItalic,
+		"color":   directiveColor,
+		"shade":   directiveShade,
+		"align":   directiveAlign,
+		"valign":  directiveVerticalAlign,
+		"hide-row": func(ctx *DirectiveContext) error {
+			ctx.RemoveRow = ctx.Flag()
+			return nil
+		},
+	}
+)
+
+// RegisterDirective - регистрация общей директивы ячейки таблицы для всех шаблонов
+// Директива с тем же именем заменяется, в том числе встроенная
+func RegisterDirective(name string, directive Directive) {
+	directivesMutex.Lock()
+	defer directivesMutex.Unlock()
+	directives[name] = directive
+}
+
+// RegisterDirective (SimpleDocxFile) - регистрация директивы ячейки таблицы для шаблона
+// Директивы шаблона имеют приоритет над общими
+func (f *SimpleDocxFile) RegisterDirective(name string, directive Directive) {
+	if f.directives == nil {
+		f.directives = make(map[string]Directive)
+	}
+	f.directives[name] = directive
+}
+
+// mergeDirectives - общие директивы вместе с директивами шаблона
+func mergeDirectives(local map[string]Directive) map[string]Directive {
+	directivesMutex.RLock()
+	defer directivesMutex.RUnlock()
+	result := make(map[string]Directive, len(directives)+len(local))
+	for name, directive := range directives {
+		result[name] = directive
+	}
+	for name, directive := range local {
+		result[name] = directive
+	}
+	return result
+}
+
+// Flag (DirectiveContext) - флаг директивы: без аргументов или с истинным значением
+// ([bold], [bold:true], [bold:{{Items$Overdue}}]; пустое значение - ложь)
+func (ctx *DirectiveContext) Flag() bool {
+	return !strings.Contains(ctx.Raw, ":") || isTrueText(ctx.Value)
+}
+
+// isTrueText - истинность выведенного в текст значения
+func isTrueText(text string) bool {
+	text = strings.TrimSpace(text)
+	return len(text) > 0 && text != "false" && text != "0"
+}
+
+// applyDirectives - выполнение директив ячеек таблицы
+// Директивы удаляются из текста ячеек, неизвестные [текст] остаются как есть
+func (r *renderer) applyDirectives(table *TableItem) error {
+	for rowIndex := len(table.Rows) - 1; rowIndex >= 0; rowIndex-- {
+		row := table.Rows[rowIndex]
+		if row == nil {
+			continue
+		}
+		state := make(map[string]interface{})
+		remove := false
+		for cellIndex, cell := range row.Cells {
+			text := plainTextFromTableCell(cell)
+			calls := make([]*DirectiveContext, 0)
+			for _, match := range rxDirective.FindAllStringSubmatch(text, -1) {
+				if _, ok := r.directives[match[1]]; ok {
+					ctx := &DirectiveContext{Table: table, Row: row, Cell: cell, RowIndex: rowIndex,
+						CellIndex: cellIndex, Raw: match[0], Name: match[1], Value: strings.TrimSpace(match[2]), Text: text, State: state}
+					if len(ctx.Value) > 0 {
+						for _, arg := range strings.Split(ctx.Value, ",") {
+							ctx.Args = append(ctx.Args, strings.TrimSpace(arg))
+						}
+					}
+					calls = append(calls, ctx)
+				}
+			}
+			if len(calls) == 0 {
+				continue
+			}
+			for _, item := range cell.Items {
+				replaceTemplateInDocItem(rxDirective, item, func(expr string) string {
+					if _, ok := r.directives[rxDirective.FindStringSubmatch(expr)[1]]; ok {
+						return ""
+					}
+					return expr
+				})
+			}
+			for _, ctx := range calls {
+				if err := r.directives[ctx.Name](ctx); err != nil {
+					return err
+				}
+				remove = remove || ctx.RemoveRow
+			}
+		}
+		if remove {
+			table.Rows = append(table.Rows[:rowIndex], table.Rows[rowIndex+1:]...)
+		}
+	}
+	return nil
+}
+
+/* ВСТРОЕННЫЕ ДИРЕКТИВЫ */
+
+// directiveNone - директива без действия ([index:ключ] - аргумент [v-merge])
+func directiveNone(ctx *DirectiveContext) error {
+	return nil
+}
+
+// directiveVerticalMerge - [v-merge]: объединение с ячейкой выше при совпадении текста или ключа [index:ключ]
+func directiveVerticalMerge(ctx *DirectiveContext) error {
+	cell := ctx.Cell
+	if ctx.RowIndex > 0 {
+		topCell := cellAtColumn(ctx.Table.Rows[ctx.RowIndex-1], gridColumn(ctx.Row, ctx.CellIndex))
+		if topCell != nil && mergeKey(ctx.Text) == mergeKey(plainTextFromTableCell(topCell)) {
+			cell.Params.VerticalMerge = new(StringValue)
+			for _, i := range cell.Items {
+				clearTextFromDocItem(i)
+			}
+			return nil
+		}
+	}
+	cell.Params.VerticalMerge = new(StringValue)
+	cell.Params.VerticalMerge.Value = "restart"
+	return nil
+}
+
+// directiveBoldRight - [BR]: жирный шрифт в ячейке и всех ячейках справа до следующего [BR]
+func directiveBoldRight(ctx *DirectiveContext) error {
+	on, _ := ctx.State["BR"].(bool)
+	on = !on
+	ctx.State["BR"] = on
+	if on {
+		for index := ctx.CellIndex; index < len(ctx.Row.Cells); index++ {
+			cell := ctx.Row.Cells[index]
+			if index > ctx.CellIndex && rxBrCellV.MatchString(plainTextFromTableCell(cell)) {
+				break
+			}
+			setBoldToCell(true, cell)
+		}
+	}
+	return nil
+}
+
+// directiveBold - [bold], [bold:флаг]: жирный шрифт в ячейке
+func directiveBold(ctx *DirectiveContext) error {
+	if ctx.Flag() {
+		ctx.Cell.SetBold(true)
+	}
+	return nil
+}
+
+// directiveItalic - [italic], [italic:флаг]: курсив в ячейке
+func directiveItalic(ctx *DirectiveContext) error {
+	if ctx.Flag() {
+		ctx.Cell.SetItalic(true)
+	}
+	return nil
+}
+
+// directiveColor - [color:FF0000]: цвет текста в ячейке
+func directiveColor(ctx *DirectiveContext) error {
+	if len(ctx.Value) > 0 {
+		ctx.Cell.SetColor(ctx.Value)
+	}
+	return nil
+}
+
+// directiveShade - [shade:FFFF00]: заливка ячейки
+func directiveShade(ctx *DirectiveContext) error {
+	if len(ctx.Value) > 0 {
+		ctx.Cell.SetShading(ctx.Value)
+	}
+	return nil
+}
+
+// directiveAlign - [align:center]: выравнивание параграфов ячейки (left, center, right, both)
+func directiveAlign(ctx *DirectiveContext) error {
+	if len(ctx.Value) > 0 {
+		ctx.Cell.SetAlign(ctx.Value)
+	}
+	return nil
+}
+
+// directiveVerticalAlign - [valign:center]: вертикальное выравнивание ячейки (top, center, bottom)
+func directiveVerticalAlign(ctx *DirectiveContext) error {
+	if len(ctx.Value) > 0 {
+		ctx.Cell.SetVerticalAlign(ctx.Value)
+	}
+	return nil
+}
+
+// forEachRecordInCell - обход записей ячейки
+func forEachRecordInCell(cell *TableCell, fn func(record *RecordItem)) {
+	for _, item := range cell.Items {
+		forEachRecord(item, fn)
+	}
+}
+
+// forEachRecord - обход записей элемента документа
+func forEachRecord(item DocItem, fn func(record *RecordItem)) {
+	switch elem := item.(type) {
+	case *ParagraphItem:
+		{
+			for _, i := range elem.Items {
+				forEachRecord(i, fn)
+			}
+		}
+	case *ContainerItem:
+		{
+			for _, i := range elem.Items {
+				forEachRecord(i, fn)
+			}
+		}
+	case *RecordItem:
+		{
+			fn(elem)
+		}
+	}
+}
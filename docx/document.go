@@ -15,6 +15,8 @@ const (
 	Record
 	Table
 	BookMark
+	Container
+	Raw
 )
 
 // DocItem - интерфейс элемента документа
@@ -24,13 +26,15 @@ type DocItem interface {
 	PlainText() string
 	Clone() DocItem
 	decode(decoder *xml.Decoder) error
-	encode(encoder *xml.Encoder) error
+	encode(encoder *xmlEncoder) error
 }
 
 // Document - документ разметки DOCX
 type Document struct {
 	Scheme     map[string]string
 	SkipScheme string
+	Extra      []DocItem
+	attrs      []xml.Attr
 	Body       Body `xml:"body"`
 }
 
@@ -40,34 +44,106 @@ type Body struct {
 	Params BodyParams `xml:"sectPr"`
 }
 
-// BodyParams - параметры тела документа
+// BodyParams - параметры тела документа (параметры секции)
 type BodyParams struct {
-	HeaderReference *ReferenceValue `xml:"headerReference,omitempty"`
-	FooterReference *ReferenceValue `xml:"footerReference,omitempty"`
-	PageSize        SizeValue       `xml:"pgSz"`
-	PageMargin      MarginValue     `xml:"pgMar"`
-	Bidi            IntValue        `xml:"bidi"`
+	HeaderReference []*ReferenceValue `xml:"headerReference,omitempty"`
+	FooterReference []*ReferenceValue `xml:"footerReference,omitempty"`
+	PageSize        SizeValue         `xml:"pgSz"`
+	PageMargin      MarginValue       `xml:"pgMar"`
+	Bidi            IntValue          `xml:"bidi"`
+	Extra           Properties        `xml:"-"`
+}
+
+// UnmarshalXML - декодирование с сохранением неизвестных свойств
+func (b *BodyParams) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	type plain BodyParams
+	return decodeProperties(decoder, start, (*plain)(b), &b.Extra)
 }
 
 func (b *BodyParams) ToWBodyParams() WBodyParams {
 	wp := WBodyParams{PageSize: WSizeValue(b.PageSize),
 		PageMargin: WMarginValue(b.PageMargin),
-		Bidi:       WIntValue(b.Bidi)}
-	if b.HeaderReference != nil {
-		wp.HeaderReference = (*WReferenceValue)(b.HeaderReference)
+		Bidi:       WIntValue(b.Bidi),
+		Extra:      b.Extra.unchanged(b)}
+	for _, ref := range b.HeaderReference {
+		wp.HeaderReference = append(wp.HeaderReference, (*WReferenceValue)(ref))
 	}
-	if b.FooterReference != nil {
-		wp.FooterReference = (*WReferenceValue)(b.FooterReference)
+	for _, ref := range b.FooterReference {
+		wp.FooterReference = append(wp.FooterReference, (*WReferenceValue)(ref))
 	}
 	return wp
 }
 
+// Clone (BodyParams) - клонирование параметров секции
+func (b *BodyParams) Clone() *BodyParams {
+	result := new(BodyParams)
+	for _, ref := range b.HeaderReference {
+		if ref != nil {
+			r := *ref
+			result.HeaderReference = append(result.HeaderReference, &r)
+		}
+	}
+	for _, ref := range b.FooterReference {
+		if ref != nil {
+			r := *ref
+			result.FooterReference = append(result.FooterReference, &r)
+		}
+	}
+	result.PageSize.From(&b.PageSize)
+	result.PageMargin.From(&b.PageMargin)
+	result.Bidi.From(&b.Bidi)
+	result.Extra = b.Extra.Clone()
+	return result
+}
+
 type WBodyParams struct {
-	HeaderReference *WReferenceValue `xml:"w:headerReference,omitempty"`
-	FooterReference *WReferenceValue `xml:"w:footerReference,omitempty"`
-	PageSize        WSizeValue       `xml:"w:pgSz"`
-	PageMargin      WMarginValue     `xml:"w:pgMar"`
-	Bidi            WIntValue        `xml:"w:bidi"`
+	HeaderReference []*WReferenceValue `xml:"w:headerReference,omitempty"`
+	FooterReference []*WReferenceValue `xml:"w:footerReference,omitempty"`
+	PageSize        WSizeValue         `xml:"w:pgSz"`
+	PageMargin      WMarginValue       `xml:"w:pgMar"`
+	Bidi            WIntValue          `xml:"w:bidi"`
+	Extra           Properties         `xml:"-"`
+}
+
+// marshalXML - кодирование вместе с неизвестными свойствами
+func (wp WBodyParams) marshalXML(encoder *xmlEncoder, start xml.StartElement) error {
+	return encodeProperties(encoder, start, &wp, &wp.Extra, bodyParamsOrder)
+}
+
+// Sections (Document) - параметры секций документа по порядку
+// Секции завершаются параграфами с w:sectPr, последняя - w:sectPr тела
+func (doc *Document) Sections() []*BodyParams {
+	sections := make([]*BodyParams, 0)
+	for _, item := range doc.Body.Items {
+		if p, ok := item.(*ParagraphItem); ok && p.Params.SectPr != nil {
+			sections = append(sections, p.Params.SectPr)
+		}
+	}
+	return append(sections, &doc.Body.Params)
+}
+
+// Clone (Document) - клонирование документа
+func (doc *Document) Clone() *Document {
+	result := new(Document)
+	result.Scheme = make(map[string]string)
+	for key, val := range doc.Scheme {
+		result.Scheme[key] = val
+	}
+	result.SkipScheme = doc.SkipScheme
+	result.attrs = append([]xml.Attr(nil), doc.attrs...)
+	for _, item := range doc.Extra {
+		if item != nil {
+			result.Extra = append(result.Extra, item.Clone())
+		}
+	}
+	result.Body.Items = make([]DocItem, 0)
+	for _, item := range doc.Body.Items {
+		if item != nil {
+			result.Body.Items = append(result.Body.Items, item.Clone())
+		}
+	}
+	result.Body.Params = *doc.Body.Params.Clone()
+	return result
 }
 
 /* ДЕКОДИРОВАНИЕ */
@@ -86,18 +162,18 @@ func (doc *Document) Decode(reader io.Reader) error {
 			case xml.StartElement:
 				{
 					if element.Name.Local == "document" {
-						for _, attr := range element.Attr {
-							if attr.Name.Local == "Ignorable" {
-								doc.SkipScheme = attr.Value
-							} else {
-								doc.Scheme[attr.Name.Local] = attr.Value
-							}
-						}
+						doc.SkipScheme, doc.attrs = decodeRootAttrs(&element, doc.Scheme)
 					} else if element.Name.Local == "body" {
 						err := doc.Body.decode(decoder)
 						if err != nil {
 							return err
 						}
+					} else {
+						// Прочие элементы документа (w:background)
+						item := decodeItem(&element, decoder)
+						if item != nil {
+							doc.Extra = append(doc.Extra, item)
+						}
 					}
 				}
 			}
@@ -144,12 +220,51 @@ func (body *Body) decode(decoder *xml.Decoder) error {
 	return errors.New("Not have decoder")
 }
 
+// containerTags - элементы, вложенные элементы которых декодируются и рендерятся
+var containerTags = map[string]bool{
+	"footnote": true, "endnote": true, "comment": true,
+	"hyperlink": true, "sdt": true, "sdtContent": true, "smartTag": true, "customXml": true,
+	"fldSimple": true, "ins": true, "del": true, "moveFrom": true, "moveTo": true,
+	"dir": true, "bdo": true,
+}
+
+// isWordElement - элемент из пространства имен wordprocessingml
+func isWordElement(element *xml.StartElement) bool {
+	prefix, ok := namespacePrefixes[element.Name.Space]
+	return len(element.Name.Space) == 0 || (ok && prefix == "w")
+}
+
+// itemAttrs - атрибуты элемента: исходные атрибуты attrs с текущими значениями полей values
+// Пустые значения не записываются, значения, которых не было в attrs, добавляются в конец
+func itemAttrs(attrs []xml.Attr, values ...xml.Attr) []xml.Attr {
+	result := make([]xml.Attr, 0, len(attrs)+len(values))
+	used := make([]bool, len(values))
+	for _, attr := range attrs {
+		field := false
+		for index, value := range values {
+			if attr.Name == value.Name {
+				attr.Value, used[index], field = value.Value, true, true
+			}
+		}
+		if !field || len(attr.Value) > 0 {
+			result = append(result, attr)
+		}
+	}
+	for index, value := range values {
+		if !used[index] && len(value.Value) > 0 {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
 func decodeItem(element *xml.StartElement, decoder *xml.Decoder) DocItem {
 	if element != nil && decoder != nil {
 		var item DocItem
 		if element.Name.Local == "p" {
 			item = new(ParagraphItem)
 			pitem := item.(*ParagraphItem)
+			pitem.Attrs = element.Copy().Attr
 			for _, attr := range element.Attr {
 				if attr.Name.Local == "rsidR" {
 					pitem.RsidR = attr.Value
@@ -168,6 +283,15 @@ func decodeItem(element *xml.StartElement, decoder *xml.Decoder) DocItem {
 			item = new(RecordItem)
 		} else if element.Name.Local == "tbl" {
 			item = new(TableItem)
+		} else if element.Name.Local == "bookmarkStart" {
+			item = &BookMarkStart{IBookMark: decodeBookMark(element)}
+		} else if element.Name.Local == "bookmarkEnd" {
+			item = &BookMarkEnd{IBookMark: decodeBookMark(element)}
+		} else if containerTags[element.Name.Local] && isWordElement(element) {
+			item = &ContainerItem{Name: element.Name.Local, Attrs: element.Copy().Attr}
+		} else {
+			// Неизвестный элемент сохраняется как есть
+			item = newRawItem(element)
 		}
 		if item != nil {
 			if item.decode(decoder) == nil {
@@ -182,21 +306,21 @@ func decodeItem(element *xml.StartElement, decoder *xml.Decoder) DocItem {
 
 // Encode - кодирование
 func (doc *Document) Encode(writer io.Writer) error {
-	encoder := xml.NewEncoder(writer)
+	encoder := newXMLEncoder(writer)
 	if encoder != nil {
 		// Начало документа
-		var attrs = make([]xml.Attr, 0)
-		for key, val := range doc.Scheme {
-			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + key}, Value: val})
-		}
-		if len(doc.SkipScheme) > 0 {
-			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "mc:Ignorable"}, Value: doc.SkipScheme})
-		}
-		docStart := xml.StartElement{Name: xml.Name{Local: "w:document"}, Attr: attrs}
+		attrs := encodeRootAttrs(doc.Scheme, doc.SkipScheme, doc.attrs)
+		docStart := xml.StartElement{Name: xml.Name{Space: nsW, Local: "document"}, Attr: attrs}
 		err := encoder.EncodeToken(docStart)
 		if err != nil {
 			return err
 		}
+		// Прочие элементы документа
+		for _, item := range doc.Extra {
+			if err := item.encode(encoder); err != nil {
+				return err
+			}
+		}
 		// Отдаем кодирование глубже - элементам
 		err = doc.Body.encode(encoder)
 		if err != nil {
@@ -213,11 +337,11 @@ func (doc *Document) Encode(writer io.Writer) error {
 }
 
 // Кодирование BODY
-func (body *Body) encode(encoder *xml.Encoder) error {
+func (body *Body) encode(encoder *xmlEncoder) error {
 	if encoder != nil {
 		// Начало BODY
-		bodyStart := xml.StartElement{Name: xml.Name{Local: "w:" + "body"}}
-		if err := encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "w:" + "body"}}); err != nil {
+		bodyStart := xml.StartElement{Name: xml.Name{Space: nsW, Local: "body"}}
+		if err := encoder.EncodeToken(xml.StartElement{Name: xml.Name{Space: nsW, Local: "body"}}); err != nil {
 			return err
 		}
 		// Переходим к элементам
@@ -227,7 +351,7 @@ func (body *Body) encode(encoder *xml.Encoder) error {
 			}
 		}
 		// Кодируем параметры
-		if err := encoder.EncodeElement(body.Params.ToWBodyParams(), xml.StartElement{Name: xml.Name{Local: "w:" + "sectPr"}}); err != nil {
+		if err := encoder.EncodeElement(body.Params.ToWBodyParams(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "sectPr"}}); err != nil {
 			return err
 		}
 		// Конец BODY
@@ -0,0 +1,130 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testCanonicalXML - элементы, атрибуты и текст части без объявлений пространств имен
+// Сравниваются части, запись которых может отличаться префиксами, кавычками и экранированием
+func testCanonicalXML(t testing.TB, data []byte) []string {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	result := make([]string, 0)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return result
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			line := "<" + element.Name.Space + " " + element.Name.Local
+			for _, attr := range element.Attr {
+				if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+					line += " " + attr.Name.Space + " " + attr.Name.Local + `="` + attr.Value + `"`
+				}
+			}
+			result = append(result, line)
+		case xml.EndElement:
+			result = append(result, "</"+element.Name.Local)
+		case xml.CharData:
+			if len(strings.TrimSpace(string(element))) > 0 {
+				result = append(result, string(element))
+			}
+		}
+	}
+}
+
+// testParts - xml части пакета DOCX
+func testParts(t testing.TB, data []byte) map[string][]byte {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := make(map[string][]byte)
+	for _, file := range z.File {
+		if !strings.HasSuffix(file.Name, ".xml") && !strings.HasSuffix(file.Name, ".rels") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result[file.Name], err = io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return result
+}
+
+// testSameXML - одинаковое содержимое частей source и result
+func testSameXML(t testing.TB, source, result []byte) {
+	t.Helper()
+	want, got := testCanonicalXML(t, source), testCanonicalXML(t, result)
+	for index := 0; index < len(want) || index < len(got); index++ {
+		var a, b string
+		if index < len(want) {
+			a = want[index]
+		}
+		if index < len(got) {
+			b = got[index]
+		}
+		if a != b {
+			t.Fatalf("token %d:\nwant %s\n got %s", index, a, b)
+		}
+	}
+}
+
+func TestWriteUnchangedExample(t *testing.T) {
+	source, err := os.ReadFile("../demo/example.docx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenBytes(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := new(bytes.Buffer)
+	if err := f.Write(buffer); err != nil {
+		t.Fatal(err)
+	}
+	sourceParts, resultParts := testParts(t, source), testParts(t, buffer.Bytes())
+	for name, data := range sourceParts {
+		t.Run(name, func(t *testing.T) {
+			result, ok := resultParts[name]
+			if !ok {
+				t.Fatal("part not written")
+			}
+			testSameXML(t, data, result)
+		})
+	}
+}
+
+func TestWriteUnchangedAttributes(t *testing.T) {
+	body := `<w:p w14:paraId="1A2B3C4D" w14:textId="77777777" w:rsidR="00A1"><w:pPr><w:shd w:val="clear" w:fill="FFFF00"/>` +
+		`<w:spacing w:after="120"/><w:jc w:val="center"/></w:pPr><w:r><w:t>a</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>b</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:sectPr/></w:pPr></w:p>` +
+		`<w:tbl><w:tblPr><w:tblW w:w="0" w:type="auto"/></w:tblPr><w:tblGrid><w:gridCol w:w="3000"/></w:tblGrid>` +
+		`<w:tr w:rsidR="00B2" w:rsidTrPr="00C3" w14:paraId="2B3C4D5E"><w:tc><w:p><w:r><w:t>c</w:t></w:r></w:p></w:tc></w:tr>` +
+		`<w:tr><w:tc><w:tcPr><w:shd w:val="clear" w:fill="auto"/></w:tcPr><w:p/></w:tc></w:tr></w:tbl>` +
+		`<w:sectPr/>`
+	document := xmlHeader + `<w:document ` + testNamespaces +
+		` xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml"><w:body>` + body + `</w:body></w:document>`
+	f, err := OpenBytes(testZip(t, document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSameXML(t, []byte(document), []byte(testDocumentXML(t, f)))
+}
@@ -0,0 +1,219 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testNamespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+// testParagraph - параграф с одной записью
+func testParagraph(text string) string {
+	return `<w:p><w:r><w:t xml:space="preserve">` + text + `</w:t></w:r></w:p>`
+}
+
+// testTable - таблица, строки которой состоят из ячеек с одним параграфом
+func testTable(rows ...[]string) string {
+	result := `<w:tbl><w:tblPr><w:tblW w:w="9000" w:type="dxa"/></w:tblPr><w:tblGrid>`
+	for range rows[0] {
+		result += `<w:gridCol w:w="3000"/>`
+	}
+	result += `</w:tblGrid>`
+	for _, row := range rows {
+		result += `<w:tr>`
+		for _, cell := range row {
+			result += `<w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/></w:tcPr>` + testParagraph(cell) + `</w:tc>`
+		}
+		result += `</w:tr>`
+	}
+	return result + `</w:tbl>`
+}
+
+// testPackage - минимальный DOCX с телом документа body
+func testPackage(t testing.TB, body string, media ...[]byte) []byte {
+	t.Helper()
+	return testZip(t, testDocument(body), media...)
+}
+
+// testDocument - word/document.xml с телом документа body
+func testDocument(body string) string {
+	return xmlHeader + `<w:document ` + testNamespaces + `><w:body>` + body +
+		`<w:sectPr><w:pgSz w:w="11900" w:h="16840"/><w:pgMar w:top="1134" w:left="1134" w:bottom="1134" w:right="1134"/></w:sectPr></w:body></w:document>`
+}
+
+// testZip - минимальный DOCX с частью word/document.xml
+func testZip(t testing.TB, document string, media ...[]byte) []byte {
+	t.Helper()
+	return testZipAt(t, time.Time{}, document, media...)
+}
+
+// testZipAt - минимальный DOCX, у частей которого время изменения modified
+func testZipAt(t testing.TB, modified time.Time, document string, media ...[]byte) []byte {
+	t.Helper()
+	parts := testPackageParts(document, "")
+	for _, data := range media {
+		parts = append(parts, testPart{"word/media/image1.bin", string(data)})
+	}
+	return testWriteZip(t, modified, parts...)
+}
+
+// testPart - часть пакета DOCX
+type testPart struct {
+	name, data string
+}
+
+// testPackageParts - части минимального DOCX с word/document.xml и связями документа relations (элементы Relationship)
+func testPackageParts(document string, relations string) []testPart {
+	return []testPart{
+		{"[Content_Types].xml", xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`},
+		{"_rels/.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/></Relationships>`},
+		{"word/_rels/document.xml.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			relations + `</Relationships>`},
+		{"word/document.xml", document},
+	}
+}
+
+// testWriteZip - архив из частей parts со временем изменения modified
+func testWriteZip(t testing.TB, modified time.Time, parts ...testPart) []byte {
+	t.Helper()
+	buffer := new(bytes.Buffer)
+	z := zip.NewWriter(buffer)
+	for _, part := range parts {
+		w, err := z.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, part.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// testOpen - открытие DOCX с телом документа body
+func testOpen(t testing.TB, body string) *SimpleDocxFile {
+	t.Helper()
+	f, err := OpenBytes(testPackage(t, body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// testDocumentXML - word/document.xml после записи файла
+func testDocumentXML(t testing.TB, f *SimpleDocxFile) string {
+	t.Helper()
+	return testPartXML(t, testWrite(t, f), "word/document.xml")
+}
+
+// testWrite - пакет после записи файла
+func testWrite(t testing.TB, f *SimpleDocxFile) []byte {
+	t.Helper()
+	buffer := new(bytes.Buffer)
+	if err := f.Write(buffer); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// testPartXML - часть name пакета data
+func testPartXML(t testing.TB, data []byte, name string) string {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range z.File {
+		if file.Name == name {
+			reader, err := file.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(data)
+		}
+	}
+	t.Fatal(name + " not found")
+	return ""
+}
+
+// testText - текст документа после записи: параграфы через "\n", ячейки таблицы через "|"
+func testText(t testing.TB, f *SimpleDocxFile) string {
+	t.Helper()
+	return testXMLText(t, testDocumentXML(t, f))
+}
+
+// testXMLText - текст части документа: параграфы через "\n", ячейки таблицы через "|"
+func testXMLText(t testing.TB, data string) string {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(data))
+	lines := make([]string, 0)
+	var line, cells []string
+	var text bool
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "tr":
+				cells = make([]string, 0)
+			case "p":
+				line = make([]string, 0)
+			case "t":
+				text = true
+			}
+		case xml.CharData:
+			if text {
+				line = append(line, string(element))
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "t":
+				text = false
+			case "p":
+				if cells != nil {
+					cells = append(cells, strings.Join(line, ""))
+				} else {
+					lines = append(lines, strings.Join(line, ""))
+				}
+			case "tr":
+				lines = append(lines, strings.Join(cells, "|"))
+				cells = nil
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// testRender - текст документа с телом body после рендера по данным v
+func testRender(t testing.TB, body string, v interface{}, opts ...RenderOption) (string, error) {
+	t.Helper()
+	f := testOpen(t, body)
+	if err := f.Render(v, opts...); err != nil {
+		return "", err
+	}
+	return testText(t, f), nil
+}
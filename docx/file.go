@@ -2,39 +2,103 @@ package docx
 
 import (
 	"archive/zip"
-	"bufio"
 	"bytes"
 	"errors"
 	"io"
-	"io/ioutil"
+	"io/fs"
 	"os"
+	"sort"
 	"strings"
 )
 
-var (
-	emptyTags = []string{"top", "left", "bottom", "right", "insideV", "insideH",
-		"shd", "jc", "vAlign", "vMerge", "noWrap", "docGrid",
-		"b", "bCs", "i", "u", "sz", "szCs", "color", "hideMark",
-		"tblLayout", "tblHeader", "tblInd", "tblW", "gridCol", "gridSpan",
-		"pStyle", "rFonts", "rtl", "tcW", "bidi", "trHeight", "lang",
-		"pgSz", "pgMar", "headerReference", "footerReference", "br", "tab"}
-)
-
 // SimpleDocxFile - файл docx
 type SimpleDocxFile struct {
-	zipFile  *zip.ReadCloser
-	headers  map[string]*Header
-	document *Document
+	zipFile   *zip.Reader
+	headers   map[string]*Header
+	footers   map[string]*Header
+	notes     map[string]*Header
+	relations *Relationships
+	document  *Document
+	// closer - исходный файл, открытый OpenFile
+	closer io.Closer
+	// deterministic - фиксированное время изменения частей при записи
+	deterministic bool
+	// directives - директивы ячеек таблицы шаблона
+	directives map[string]Directive
+	// rowHooks, cellHooks - обработчики строк и ячеек, выведенных из массивов
+	rowHooks  []RowHook
+	cellHooks []CellHook
+	// helpers - помощники шаблона
+	helpers map[string]interface{}
 }
 
 // OpenFile - Открытие файла DOCX
-func OpenFile(fileName string) (*SimpleDocxFile, error) {
+// Файл остается открытым до вызова Close, если не задан параметр InMemory
+func OpenFile(fileName string, opts ...OpenOption) (*SimpleDocxFile, error) {
+	o := newOpenOptions(opts)
+	if o.inMemory {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		return OpenBytes(data, opts...)
+	}
 	z, err := zip.OpenReader(fileName)
 	if err != nil {
 		return nil, err
 	}
+	d, err := openZip(&z.Reader, o)
+	if err != nil {
+		z.Close()
+		return nil, err
+	}
+	d.closer = z
+	return d, nil
+}
+
+// OpenReader - открытие DOCX из io.ReaderAt
+// С параметром InMemory данные читаются сразу и r после открытия не используется
+func OpenReader(r io.ReaderAt, size int64, opts ...OpenOption) (*SimpleDocxFile, error) {
+	o := newOpenOptions(opts)
+	if o.inMemory {
+		data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return nil, err
+		}
+		return OpenBytes(data, opts...)
+	}
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return openZip(z, o)
+}
+
+// OpenBytes - открытие DOCX из среза байт
+func OpenBytes(data []byte, opts ...OpenOption) (*SimpleDocxFile, error) {
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	return openZip(z, newOpenOptions(opts))
+}
+
+// OpenFS - открытие DOCX из файловой системы fs.FS (например embed.FS)
+func OpenFS(fsys fs.FS, name string, opts ...OpenOption) (*SimpleDocxFile, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return OpenBytes(data, opts...)
+}
+
+// openZip - разбор частей zip архива DOCX
+func openZip(z *zip.Reader, o *openOptions) (*SimpleDocxFile, error) {
 	d := new(SimpleDocxFile)
+	d.deterministic = o.deterministic
 	d.headers = make(map[string]*Header)
+	d.footers = make(map[string]*Header)
+	d.notes = make(map[string]*Header)
 	d.zipFile = z
 	// Перебор файлов в Zip архиве
 	for _, f := range z.File {
@@ -61,77 +125,271 @@ func OpenFile(fileName string) (*SimpleDocxFile, error) {
 					return nil, err
 				}
 				d.headers[f.Name] = header
+			} else if strings.Index(f.Name, "word/footer") >= 0 {
+				reader, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				footer := new(Header)
+				footer.Decode(reader)
+				if err := reader.Close(); err != nil {
+					return nil, err
+				}
+				d.footers[f.Name] = footer
+			} else if f.Name == "word/footnotes.xml" || f.Name == "word/endnotes.xml" || f.Name == "word/comments.xml" {
+				reader, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				note := new(Header)
+				note.Decode(reader)
+				if err := reader.Close(); err != nil {
+					return nil, err
+				}
+				d.notes[f.Name] = note
+			} else if f.Name == "word/_rels/document.xml.rels" {
+				reader, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				d.relations = new(Relationships)
+				if err := d.relations.Decode(reader); err != nil {
+					reader.Close()
+					return nil, err
+				}
+				if err := reader.Close(); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
 	return d, nil
 }
 
+// Close (SimpleDocxFile) - закрытие исходного файла
+// Файлы, открытые из памяти, закрывать не обязательно
+func (f *SimpleDocxFile) Close() error {
+	if f.closer != nil {
+		err := f.closer.Close()
+		f.closer = nil
+		return err
+	}
+	return nil
+}
+
 // Render (SimpleDocxFile) - рендер шаблона
-func (f *SimpleDocxFile) Render(v interface{}) error {
-	return renderTemplateDocument(f.document, v)
+// Одни и те же данные применяются к телу документа, заголовкам, подвалам,
+// сноскам и комментариям; параметрами можно пропустить часть или задать для нее другие данные
+func (f *SimpleDocxFile) Render(v interface{}, opts ...RenderOption) error {
+	o := newRenderOptions(opts)
+	r := f.newRenderer(o)
+	if !o.skipped("word/document.xml", PartDocument) {
+		if err := r.renderTemplateDocument(f.document, o.dataFor("word/document.xml", PartDocument, v)); err != nil {
+			return err
+		}
+	}
+	for _, name := range f.partNames() {
+		kind := f.partKind(name)
+		if o.skipped(name, kind) {
+			continue
+		}
+		if err := r.renderTemplateHeader(f.part(name), o.dataFor(name, kind, v)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// RenderHeader (SimpleDocxFile) - рендер заголовка шаблона
-func (f *SimpleDocxFile) RenderHeader(index int, v interface{}) error {
-	pos := 0
-	for _, header := range f.headers {
-		if header != nil {
-			if pos == index {
-				return renderTemplateHeader(header, v)
+// RenderMerge (SimpleDocxFile) - слияние: тело документа повторяется для каждой записи
+// Копии разделяются разрывом страницы или новой секцией (SeparateBy), параметры секций
+// и ссылки на колонтитулы каждой копии сохраняются. Колонтитулы общие для всех копий
+// и рендерятся отдельно (RenderHeader, RenderFooter)
+func (f *SimpleDocxFile) RenderMerge(records []interface{}, opts ...RenderOption) error {
+	if f.document == nil {
+		return errors.New("Not valid template document")
+	}
+	o := newRenderOptions(opts)
+	r := f.newRenderer(o)
+	items := make([]DocItem, 0)
+	for index, record := range records {
+		if index > 0 {
+			items = append(items, mergeSeparator(o.separator, &f.document.Body.Params))
+		}
+		doc := new(Document)
+		for _, item := range f.document.Body.Items {
+			if item != nil {
+				doc.Body.Items = append(doc.Body.Items, item.Clone())
+			}
+		}
+		if err := r.renderTemplateDocument(doc, record); err != nil {
+			return err
+		}
+		items = append(items, doc.Body.Items...)
+	}
+	f.document.Body.Items = items
+	return nil
+}
+
+// mergeSeparator - параграф-разделитель копий документа при слиянии
+func mergeSeparator(separator MergeSeparator, params *BodyParams) DocItem {
+	p := new(ParagraphItem)
+	if separator == MergeSection {
+		// Параграф завершает секцию предыдущей копии
+		p.Params.SectPr = params.Clone()
+	} else {
+		p.Items = []DocItem{&RecordItem{Break: true, BreakType: "page"}}
+	}
+	return p
+}
+
+// partNames - имена частей документа (кроме тела) по порядку
+func (f *SimpleDocxFile) partNames() []string {
+	names := make([]string, 0)
+	for _, parts := range []map[string]*Header{f.headers, f.footers, f.notes} {
+		for name, part := range parts {
+			if part != nil {
+				names = append(names, name)
 			}
-			pos++
 		}
 	}
+	sort.Strings(names)
+	return names
+}
+
+// part - заголовок, подвал или сноски по имени части
+func (f *SimpleDocxFile) part(name string) *Header {
+	if header, ok := f.headers[name]; ok {
+		return header
+	}
+	if footer, ok := f.footers[name]; ok {
+		return footer
+	}
+	if note, ok := f.notes[name]; ok {
+		return note
+	}
+	return nil
+}
+
+// partKind - вид части документа
+func (f *SimpleDocxFile) partKind(name string) string {
+	if _, ok := f.headers[name]; ok {
+		return PartHeader
+	}
+	if _, ok := f.footers[name]; ok {
+		return PartFooter
+	}
+	if note, ok := f.notes[name]; ok && note != nil {
+		return note.root
+	}
+	return PartDocument
+}
+
+// RenderHeader (SimpleDocxFile) - рендер заголовка шаблона по номеру (в порядке имен частей)
+//
+// Deprecated: номер не связан с секциями документа, используйте RenderSectionHeader
+func (f *SimpleDocxFile) RenderHeader(index int, v interface{}) error {
+	if header := partByIndex(f.headers, index); header != nil {
+		return f.newRenderer(newRenderOptions(nil)).renderTemplateHeader(header, v)
+	}
+	return nil
+}
+
+// RenderFooter (SimpleDocxFile) - рендер подвала шаблона по номеру (в порядке имен частей)
+//
+// Deprecated: номер не связан с секциями документа, используйте RenderSectionFooter
+func (f *SimpleDocxFile) RenderFooter(index int, v interface{}) error {
+	if footer := partByIndex(f.footers, index); footer != nil {
+		return f.newRenderer(newRenderOptions(nil)).renderTemplateHeader(footer, v)
+	}
+	return nil
+}
+
+// partByIndex - часть с номером index в порядке имен частей
+func partByIndex(parts map[string]*Header, index int) *Header {
+	names := make([]string, 0, len(parts))
+	for name, part := range parts {
+		if part != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if index >= 0 && index < len(names) {
+		return parts[names[index]]
+	}
 	return nil
 }
 
+// RenderSectionHeader (SimpleDocxFile) - рендер заголовка секции шаблона
+// Если у секции нет заголовка нужного типа, используется заголовок предыдущей секции
+func (f *SimpleDocxFile) RenderSectionHeader(section int, typ HeaderType, v interface{}, opts ...RenderOption) error {
+	if name, ok := f.headerPartName(section, typ, false); ok {
+		if header, ok := f.headers[name]; ok && header != nil {
+			return f.newRenderer(newRenderOptions(opts)).renderTemplateHeader(header, v)
+		}
+	}
+	return errors.New("Header not found")
+}
+
+// RenderSectionFooter (SimpleDocxFile) - рендер подвала секции шаблона
+// Если у секции нет подвала нужного типа, используется подвал предыдущей секции
+func (f *SimpleDocxFile) RenderSectionFooter(section int, typ HeaderType, v interface{}, opts ...RenderOption) error {
+	if name, ok := f.headerPartName(section, typ, true); ok {
+		if footer, ok := f.footers[name]; ok && footer != nil {
+			return f.newRenderer(newRenderOptions(opts)).renderTemplateHeader(footer, v)
+		}
+	}
+	return errors.New("Footer not found")
+}
+
+// HeaderParts (SimpleDocxFile) - колонтитулы, на которые ссылаются секции документа
+func (f *SimpleDocxFile) HeaderParts() []HeaderPart {
+	parts := make([]HeaderPart, 0)
+	if f.document != nil {
+		for index, section := range f.document.Sections() {
+			parts = f.appendHeaderParts(parts, section.HeaderReference, index, false)
+			parts = f.appendHeaderParts(parts, section.FooterReference, index, true)
+		}
+	}
+	return parts
+}
+
+func (f *SimpleDocxFile) appendHeaderParts(parts []HeaderPart, refs []*ReferenceValue, section int, footer bool) []HeaderPart {
+	for _, ref := range refs {
+		if ref != nil {
+			if name, ok := f.relations.PartName(ref.ID); ok {
+				parts = append(parts, HeaderPart{Name: name, Section: section, Type: HeaderType(ref.Type), Footer: footer})
+			}
+		}
+	}
+	return parts
+}
+
+// headerPartName - имя части колонтитула секции с учетом наследования от предыдущих секций
+func (f *SimpleDocxFile) headerPartName(section int, typ HeaderType, footer bool) (string, bool) {
+	if f.document != nil {
+		sections := f.document.Sections()
+		if section >= 0 && section < len(sections) {
+			for index := section; index >= 0; index-- {
+				refs := sections[index].HeaderReference
+				if footer {
+					refs = sections[index].FooterReference
+				}
+				for _, ref := range refs {
+					if ref != nil && HeaderType(ref.Type) == typ {
+						return f.relations.PartName(ref.ID)
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
+
 // Write (SimpleDocxFile)
 func (f *SimpleDocxFile) Write(writer io.Writer) error {
 	if f.zipFile != nil {
 		if f.document != nil {
-			w := zip.NewWriter(writer)
-			defer w.Close()
-			// Перебор файлов в Zip архиве
-			for _, zf := range f.zipFile.File {
-				if zf != nil {
-					// Загрузка документа
-					if zf.Name == "word/document.xml" {
-						wzf, _ := w.Create(zf.Name)
-						if wzf != nil {
-							if b, err := wordDocumentToXML(f.document); b != nil && err == nil {
-								wzf.Write([]byte("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>"))
-								wzf.Write(b)
-							}
-						}
-					} else if header, ok := f.headers[zf.Name]; ok && strings.Index(zf.Name, "word/header") >= 0 {
-						wzf, _ := w.Create(zf.Name)
-						if wzf != nil {
-							if b, err := wordHeaderToXML(header); b != nil && err == nil {
-								wzf.Write([]byte("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>"))
-								wzf.Write(b)
-							}
-						}
-					} else {
-						r, _ := zf.Open()
-						if r != nil {
-							wzf, _ := w.Create(zf.Name)
-							if wzf != nil {
-								b, err := ioutil.ReadAll(r)
-								if err == nil {
-									wzf.Write(b)
-								}
-							}
-							r.Close()
-						}
-					}
-				}
-			}
-			err := w.Flush()
-			if err != nil {
-				return err
-			}
-			return nil
+			return f.writePackage(writer, packageEntries(f.zipFile))
 		}
 		return errors.New("Not valid document")
 	}
@@ -142,46 +400,7 @@ func (f *SimpleDocxFile) Write(writer io.Writer) error {
 func (f *SimpleDocxFile) Save(fileName string) error {
 	if f.zipFile != nil {
 		if f.document != nil {
-			file, err := os.Create(fileName)
-			if err != nil {
-				return nil
-			}
-			defer file.Close()
-			w := zip.NewWriter(file)
-			defer w.Close()
-
-			// Перебор файлов в Zip архиве
-			for _, zf := range f.zipFile.File {
-				if zf != nil {
-					// Загрузка документа
-					if zf.Name == "word/document.xml" {
-						wzf, _ := w.Create(zf.Name)
-						if wzf != nil {
-							if b, err := wordDocumentToXML(f.document); b != nil && err == nil {
-								wzf.Write([]byte("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>"))
-								wzf.Write(b)
-							}
-						}
-					} else {
-						r, _ := zf.Open()
-						if r != nil {
-							wzf, _ := w.Create(zf.Name)
-							if wzf != nil {
-								b, err := ioutil.ReadAll(r)
-								if err == nil {
-									wzf.Write(b)
-								}
-							}
-							r.Close()
-						}
-					}
-				}
-			}
-			err = w.Flush()
-			if err != nil {
-				return err
-			}
-			return nil
+			return saveFile(fileName, f.Write)
 		}
 		return errors.New("Not valid document")
 	}
@@ -191,15 +410,8 @@ func (f *SimpleDocxFile) Save(fileName string) error {
 func wordHeaderToXML(h *Header) (data []byte, err error) {
 	if h != nil {
 		var buffer bytes.Buffer
-		writer := bufio.NewWriter(&buffer)
-		err = h.Encode(writer)
-		if err == nil && buffer.Len() > 0 {
+		if err = h.Encode(&buffer); err == nil && buffer.Len() > 0 {
 			data = buffer.Bytes()
-			buffer.Reset()
-			// Замены empty tags
-			for _, emptyTag := range emptyTags {
-				data = bytes.Replace(data, []byte("></"+emptyTag+">"), []byte(" />"), -1)
-			}
 		}
 	}
 	return
@@ -208,16 +420,8 @@ func wordHeaderToXML(h *Header) (data []byte, err error) {
 func wordDocumentToXML(d *Document) (data []byte, err error) {
 	if d != nil {
 		var buffer bytes.Buffer
-		writer := bufio.NewWriter(&buffer)
-		err = d.Encode(writer)
-		if err == nil && buffer.Len() > 0 {
+		if err = d.Encode(&buffer); err == nil && buffer.Len() > 0 {
 			data = buffer.Bytes()
-			buffer.Reset()
-			// Замены
-			// Замены empty tags
-			for _, emptyTag := range emptyTags {
-				data = bytes.Replace(data, []byte("></"+emptyTag+">"), []byte(" />"), -1)
-			}
 		}
 	}
 	return
@@ -0,0 +1,365 @@
+package docx
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// Части тестового документа с двумя секциями
+// Секция 0: заголовки default (header1) и first (header2), подвал default (footer1)
+// Секция 1: только заголовок default (header3), остальные колонтитулы наследуются
+var testSectionParts = []testPart{
+	{"word/header1.xml", testHeaderXML("hdr", "H1 {{Title}}")},
+	{"word/header2.xml", testHeaderXML("hdr", "H2 {{Title}}")},
+	{"word/header3.xml", testHeaderXML("hdr", "H3 {{Title}}")},
+	{"word/footer1.xml", testHeaderXML("ftr", "F1 {{Title}}")},
+	{"word/footnotes.xml", testHeaderXML("footnotes", `<w:footnote w:id="1">`+testParagraph("N {{Title}}")+`</w:footnote>`)},
+	{"word/comments.xml", testHeaderXML("comments", `<w:comment w:id="0">`+testParagraph("C {{Title}}")+`</w:comment>`)},
+}
+
+// testHeaderXML - часть документа с корнем root; текст без разметки становится параграфом
+func testHeaderXML(root string, content string) string {
+	if !strings.HasPrefix(content, "<") {
+		content = testParagraph(content)
+	}
+	return xmlHeader + `<w:` + root + ` ` + testNamespaces + `>` + content + `</w:` + root + `>`
+}
+
+// testSectionsPackage - DOCX с телом body, двумя секциями, колонтитулами, сносками и комментариями
+func testSectionsPackage(t testing.TB, body string) []byte {
+	t.Helper()
+	document := xmlHeader + `<w:document ` + testNamespaces + `><w:body>` + body +
+		`<w:p><w:pPr><w:sectPr><w:headerReference w:type="default" r:id="rId1"/><w:headerReference w:type="first" r:id="rId2"/>` +
+		`<w:footerReference w:type="default" r:id="rId3"/><w:pgSz w:w="11900" w:h="16840"/></w:sectPr></w:pPr></w:p>` +
+		testParagraph("{{Title}} 2") +
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId4"/><w:pgSz w:w="11900" w:h="16840"/></w:sectPr></w:body></w:document>`
+	relation := func(id, typ, target string) string {
+		return `<Relationship Id="` + id + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/` +
+			typ + `" Target="` + target + `"/>`
+	}
+	parts := testPackageParts(document, relation("rId1", "header", "header1.xml")+relation("rId2", "header", "header2.xml")+
+		relation("rId3", "footer", "footer1.xml")+relation("rId4", "header", "header3.xml")+
+		relation("rId5", "footnotes", "footnotes.xml")+relation("rId6", "comments", "comments.xml"))
+	return testWriteZip(t, time.Time{}, append(parts, testSectionParts...)...)
+}
+
+// testPartsText - текст частей пакета data по именам
+func testPartsText(t testing.TB, data []byte, names ...string) map[string]string {
+	t.Helper()
+	result := make(map[string]string, len(names))
+	for _, name := range names {
+		result[name] = testXMLText(t, testPartXML(t, data, name))
+	}
+	return result
+}
+
+func TestOpen(t *testing.T) {
+	data := testSectionsPackage(t, testParagraph("Body {{Title}}"))
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "template.docx")
+	if err := os.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		open func() (*SimpleDocxFile, error)
+	}{
+		{"file", func() (*SimpleDocxFile, error) { return OpenFile(fileName) }},
+		{"reader", func() (*SimpleDocxFile, error) { return OpenReader(bytes.NewReader(data), int64(len(data))) }},
+		{"bytes", func() (*SimpleDocxFile, error) { return OpenBytes(data) }},
+		{"fs", func() (*SimpleDocxFile, error) { return OpenFS(os.DirFS(dir), "template.docx") }},
+		{"map fs", func() (*SimpleDocxFile, error) {
+			return OpenFS(fstest.MapFS{"templates/a.docx": {Data: data}}, "templates/a.docx")
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := test.open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if err := f.Render(testOrdersData); err != nil {
+				t.Fatal(err)
+			}
+			result := testPartsText(t, testWrite(t, f), "word/document.xml", "word/header1.xml", "word/footer1.xml")
+			want := map[string]string{"word/document.xml": "Body T\n\nT 2", "word/header1.xml": "H1 T", "word/footer1.xml": "F1 T"}
+			for name, text := range want {
+				if result[name] != text {
+					t.Errorf("%s: got %q, want %q", name, result[name], text)
+				}
+			}
+		})
+	}
+
+	// Ошибки открытия
+	if _, err := OpenBytes([]byte("not a zip")); err == nil {
+		t.Error("OpenBytes of invalid data: want error")
+	}
+	if _, err := OpenFS(os.DirFS(dir), "missing.docx"); err == nil {
+		t.Error("OpenFS of missing file: want error")
+	}
+	if _, err := OpenFile(filepath.Join(dir, "missing.docx")); err == nil {
+		t.Error("OpenFile of missing file: want error")
+	}
+}
+
+func TestFooters(t *testing.T) {
+	f, err := OpenBytes(testSectionsPackage(t, testParagraph("Body {{Title}}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Подвал по номеру рендерится отдельно от тела документа
+	if err := f.RenderFooter(0, testOrdersData); err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(t.TempDir(), "result.docx")
+	if err := f.Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"write": testWrite(t, f), "save": saved} {
+		footer := testPartXML(t, data, "word/footer1.xml")
+		if !strings.Contains(footer, "<w:ftr ") {
+			t.Errorf("%s: footer root is not w:ftr: %s", name, footer)
+		}
+		result := testPartsText(t, data, "word/footer1.xml", "word/header1.xml", "word/document.xml")
+		want := map[string]string{"word/footer1.xml": "F1 T", "word/header1.xml": "H1 {{Title}}", "word/document.xml": "Body {{Title}}\n\n{{Title}} 2"}
+		for part, text := range want {
+			if result[part] != text {
+				t.Errorf("%s %s: got %q, want %q", name, part, result[part], text)
+			}
+		}
+	}
+}
+
+func TestSectionHeaders(t *testing.T) {
+	data := testSectionsPackage(t, testParagraph("Body"))
+	f, err := OpenBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []HeaderPart{
+		{Name: "word/header1.xml", Section: 0, Type: HeaderDefault},
+		{Name: "word/header2.xml", Section: 0, Type: HeaderFirst},
+		{Name: "word/footer1.xml", Section: 0, Type: HeaderDefault, Footer: true},
+		{Name: "word/header3.xml", Section: 1, Type: HeaderDefault},
+	}
+	if parts := f.HeaderParts(); !reflect.DeepEqual(parts, want) {
+		t.Errorf("got parts %v, want %v", parts, want)
+	}
+
+	tests := []struct {
+		name    string
+		section int
+		typ     HeaderType
+		footer  bool
+		part    string
+	}{
+		{"default", 0, HeaderDefault, false, "word/header1.xml"},
+		{"first", 0, HeaderFirst, false, "word/header2.xml"},
+		{"second section", 1, HeaderDefault, false, "word/header3.xml"},
+		{"inherited first", 1, HeaderFirst, false, "word/header2.xml"},
+		{"missing even", 0, HeaderEven, false, ""},
+		{"missing section", 2, HeaderDefault, false, ""},
+		{"footer", 0, HeaderDefault, true, "word/footer1.xml"},
+		{"inherited footer", 1, HeaderDefault, true, "word/footer1.xml"},
+		{"missing first footer", 1, HeaderFirst, true, ""},
+	}
+	names := []string{"word/header1.xml", "word/header2.xml", "word/header3.xml", "word/footer1.xml"}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := OpenBytes(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.footer {
+				err = f.RenderSectionFooter(test.section, test.typ, testOrdersData)
+			} else {
+				err = f.RenderSectionHeader(test.section, test.typ, testOrdersData)
+			}
+			if (err != nil) != (len(test.part) == 0) {
+				t.Fatalf("got error %v, want part %q", err, test.part)
+			}
+			// Рендерится только найденная часть
+			result := testPartsText(t, testWrite(t, f), names...)
+			for _, name := range names {
+				rendered := !strings.Contains(result[name], "{{Title}}")
+				if rendered != (name == test.part) {
+					t.Errorf("%s: got %q", name, result[name])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderParts(t *testing.T) {
+	data := testSectionsPackage(t, testParagraph("Body {{Title}}"))
+	other := struct{ Title string }{"O"}
+	named := struct{ Title string }{"N"}
+	names := []string{"word/document.xml", "word/header1.xml", "word/header2.xml", "word/header3.xml",
+		"word/footer1.xml", "word/footnotes.xml", "word/comments.xml"}
+	tests := []struct {
+		name string
+		opts []RenderOption
+		want []string
+	}{
+		{"all", nil,
+			[]string{"Body T\n\nT 2", "H1 T", "H2 T", "H3 T", "F1 T", "N T", "C T"}},
+		{"skip kind", []RenderOption{SkipPart(PartHeader, PartComments)},
+			[]string{"Body T\n\nT 2", "H1 {{Title}}", "H2 {{Title}}", "H3 {{Title}}", "F1 T", "N T", "C {{Title}}"}},
+		{"skip name", []RenderOption{SkipPart("word/footer1.xml", "word/header2.xml")},
+			[]string{"Body T\n\nT 2", "H1 T", "H2 {{Title}}", "H3 T", "F1 {{Title}}", "N T", "C T"}},
+		{"skip document", []RenderOption{SkipPart(PartDocument)},
+			[]string{"Body {{Title}}\n\n{{Title}} 2", "H1 T", "H2 T", "H3 T", "F1 T", "N T", "C T"}},
+		// Данные по имени части важнее данных по виду
+		{"data", []RenderOption{PartData(PartHeader, other), PartData("word/header2.xml", named), PartData(PartFootnotes, other)},
+			[]string{"Body T\n\nT 2", "H1 O", "H2 N", "H3 O", "F1 T", "N O", "C T"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := OpenBytes(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Render(testOrdersData, test.opts...); err != nil {
+				t.Fatal(err)
+			}
+			result := testPartsText(t, testWrite(t, f), names...)
+			for i, name := range names {
+				if result[name] != test.want[i] {
+					t.Errorf("%s: got %q, want %q", name, result[name], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderMerge(t *testing.T) {
+	records := []interface{}{
+		struct{ Title string }{"A"},
+		struct{ Title string }{"B"},
+		struct{ Title string }{"C"},
+	}
+	f := testOpen(t, testParagraph("Name {{Title}}"))
+	if err := f.RenderMerge(records); err != nil {
+		t.Fatal(err)
+	}
+	document := testDocumentXML(t, f)
+	if text, want := testXMLText(t, document), "Name A\n\nName B\n\nName C"; text != want {
+		t.Errorf("page break: got %q, want %q", text, want)
+	}
+	// Разрыв страницы между копиями, параметры тела не меняются
+	if count := strings.Count(document, `w:type="page"`); count != 2 {
+		t.Errorf("page break: got %d page breaks, want 2: %s", count, document)
+	}
+	if count := strings.Count(document, "<w:sectPr"); count != 1 {
+		t.Errorf("page break: got %d sectPr, want 1: %s", count, document)
+	}
+
+	// Каждая копия в своей секции: 2 секции шаблона на копию
+	f, err := OpenBytes(testSectionsPackage(t, testParagraph("Name {{Title}}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RenderMerge(records, SeparateBy(MergeSection)); err != nil {
+		t.Fatal(err)
+	}
+	document = testDocumentXML(t, f)
+	if text, want := testXMLText(t, document), "Name A\n\nA 2\n\nName B\n\nB 2\n\nName C\n\nC 2"; text != want {
+		t.Errorf("section: got %q, want %q", text, want)
+	}
+	if count := strings.Count(document, `w:type="page"`); count != 0 {
+		t.Errorf("section: got %d page breaks, want 0", count)
+	}
+	// Разделитель копирует последнюю секцию шаблона вместе с колонтитулами
+	if count := strings.Count(document, `r:id="rId4"`); count != 3 {
+		t.Errorf("section: got %d references to the last section header, want 3: %s", count, document)
+	}
+	if count := strings.Count(document, `r:id="rId1"`); count != 3 {
+		t.Errorf("section: got %d references to the first section header, want 3: %s", count, document)
+	}
+	if !strings.HasSuffix(document, `<w:headerReference w:type="default" r:id="rId4"/><w:pgSz w:w="11900" w:h="16840"/></w:sectPr></w:body></w:document>`) {
+		t.Errorf("section: body sectPr changed: %s", document)
+	}
+}
+
+func TestInMemory(t *testing.T) {
+	data := testSectionsPackage(t, testParagraph("Body {{Title}}"))
+	fileName := filepath.Join(t.TempDir(), "template.docx")
+	if err := os.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	check := func(name string, result []byte) {
+		t.Helper()
+		text := testPartsText(t, result, "word/document.xml", "word/header1.xml")
+		if text["word/document.xml"] != "Body T\n\nT 2" || text["word/header1.xml"] != "H1 T" {
+			t.Errorf("%s: got %q", name, text)
+		}
+	}
+
+	// Файл шаблона после открытия не нужен: его можно перезаписать результатом
+	f, err := OpenFile(fileName, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileName, []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Render(testOrdersData); err != nil {
+		t.Fatal(err)
+	}
+	check("write", testWrite(t, f))
+	if err := f.Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("save", saved)
+
+	// Данные читателя копируются при открытии
+	buffer := append([]byte(nil), data...)
+	f, err = OpenReader(bytes.NewReader(buffer), int64(len(buffer)), InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range buffer {
+		buffer[i] = 0
+	}
+	if err := f.Render(testOrdersData); err != nil {
+		t.Fatal(err)
+	}
+	check("reader", testWrite(t, f))
+
+	// Без InMemory части читаются из файла при записи
+	if err := os.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err = OpenFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if err := f.Write(new(bytes.Buffer)); err == nil {
+		t.Error("Write after Close: want error")
+	}
+}
@@ -0,0 +1,181 @@
+package docx
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aymerick/raymond"
+)
+
+var (
+	rxGroupBy     = regexp.MustCompile(`\[\s?group-by\s?:\s?([\w\.\$]+)\s?\]`)
+	rxGroupHeader = regexp.MustCompile(`\[\s?group-header\s?\]`)
+	rxGroupFooter = regexp.MustCompile(`\[\s?group-footer\s?\]`)
+	rxGrandTotal  = regexp.MustCompile(`\[\s?grand-total\s?\]`)
+	rxAggregate   = regexp.MustCompile(`\{\{\s*([\w\.\$]+)\s?:\s?(sum|count|min|max|avg)\s*\}\}`)
+)
+
+// summaryRows - строки заголовка и итогов групп строк массива
+// header и footer выводятся для каждой группы [group-by:поле], total - один раз после всех строк
+type summaryRows struct {
+	header *TableRow
+	footer *TableRow
+	total  *TableRow
+}
+
+// groupByField - поле группировки из флага [group-by:поле] в строках группы (флаг удаляется)
+func groupByField(group []*TableRow) string {
+	field := ""
+	for _, row := range group {
+		for _, cell := range row.Cells {
+			if match := rxGroupBy.FindStringSubmatch(plainTextFromTableCell(cell)); match != nil {
+				field = match[1]
+				removeTemplateFromCell(rxGroupBy, cell)
+			}
+		}
+	}
+	return field
+}
+
+// groupLines - деление строк массива на группы по значению поля
+// Группу образуют идущие подряд строки с одинаковым значением, без поля - одна группа
+func groupLines(lines []map[string]interface{}, field string) [][2]int {
+	result := make([][2]int, 0)
+	start := 0
+	for index := 1; index <= len(lines); index++ {
+		if index < len(lines) && (len(field) == 0 || groupKey(&lines[index], field) == groupKey(&lines[start], field)) {
+			continue
+		}
+		result = append(result, [2]int{start, index})
+		start = index
+	}
+	return result
+}
+
+// groupKey - значение поля группировки строки
+func groupKey(line *map[string]interface{}, field string) string {
+	value, _ := lookupValue(line, field)
+	return raymond.Str(value)
+}
+
+// renderSummaryRow - вывод строки итогов: {{Items$Price:sum}} считается по строкам lines,
+// остальные шаблоны выводятся по данным v
+func (r *renderer) renderSummaryRow(row *TableRow, lines []map[string]interface{}, v interface{}) error {
+	for _, cell := range row.Cells {
+		for _, item := range cell.Items {
+			replaceTemplateInDocItem(rxAggregate, item, func(expr string) string {
+				match := rxAggregate.FindStringSubmatch(expr)
+				return aggregate(lines, match[1], match[2])
+			})
+		}
+	}
+	return r.renderRow(row, v)
+}
+
+// aggregate - итоговое значение поля по строкам: sum, count, min, max, avg
+func aggregate(lines []map[string]interface{}, field string, function string) string {
+	values := make([]interface{}, 0, len(lines))
+	for index := range lines {
+		value, _ := lookupValue(&lines[index], field)
+		values = append(values, value)
+	}
+	return aggregateValues(values, function)
+}
+
+// aggregateValues - итоговое значение: count - количество значений,
+// остальные функции считаются по числовым значениям
+func aggregateValues(values []interface{}, function string) string {
+	if function == "count" {
+		return strconv.Itoa(len(values))
+	}
+	numbers := make([]float64, 0, len(values))
+	precision := 0
+	for _, value := range values {
+		if number, digits, ok := toNumber(value); ok {
+			numbers = append(numbers, number)
+			if digits > precision {
+				precision = digits
+			}
+		}
+	}
+	if function == "sum" {
+		sum := 0.0
+		for _, value := range numbers {
+			sum += value
+		}
+		return formatAggregate(sum, precision)
+	}
+	if len(numbers) == 0 {
+		return ""
+	}
+	switch function {
+	case "min":
+		min := numbers[0]
+		for _, value := range numbers[1:] {
+			min = math.Min(min, value)
+		}
+		return formatAggregate(min, precision)
+	case "max":
+		max := numbers[0]
+		for _, value := range numbers[1:] {
+			max = math.Max(max, value)
+		}
+		return formatAggregate(max, precision)
+	case "avg":
+		sum := 0.0
+		for _, value := range numbers {
+			sum += value
+		}
+		return formatAggregate(sum/float64(len(numbers)), precision+2)
+	}
+	return ""
+}
+
+// toNumber - числовое значение и количество знаков после запятой
+// Строки разбираются как числа (разделитель дробной части - точка или запятая)
+func toNumber(v interface{}) (float64, int, bool) {
+	val := reflect.ValueOf(v)
+	for val.IsValid() && (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) {
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return 0, 0, false
+	}
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), 0, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), 0, true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), decimals(strconv.FormatFloat(val.Float(), 'f', -1, val.Type().Bits())), true
+	case reflect.String:
+		text := strings.Replace(strings.TrimSpace(val.String()), ",", ".", 1)
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number, decimals(text), true
+		}
+	}
+	return 0, 0, false
+}
+
+// decimals - количество знаков после точки в записи числа
+func decimals(text string) int {
+	if index := strings.IndexByte(text, '.'); index >= 0 {
+		return len(text) - index - 1
+	}
+	return 0
+}
+
+// formatAggregate - запись числа с не более чем precision знаками после точки (без конечных нулей)
+func formatAggregate(number float64, precision int) string {
+	text := strconv.FormatFloat(number, 'f', precision, 64)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	if text == "-0" {
+		text = "0"
+	}
+	return text
+}
@@ -0,0 +1,132 @@
+package docx
+
+import (
+	"testing"
+)
+
+// testOrder - строка заказа в данных тестов итогов
+type testOrder struct {
+	Category string
+	Name     string
+	Price    float64
+	Qty      int
+}
+
+// testOrders - данные тестов итогов
+type testOrders struct {
+	Title  string
+	Orders []testOrder
+	None   []testOrder
+}
+
+var testOrdersData = &testOrders{
+	Title: "T",
+	Orders: []testOrder{
+		{"A", "a1", 1.5, 2}, {"A", "a2", 2.25, 1}, {"B", "b1", 10, 3}, {"C", "c1", 0.1, 1}, {"C", "c2", 0.2, 5},
+	},
+}
+
+func TestGroupRows(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		want string
+	}{
+		{
+			name: "header and footer",
+			rows: [][]string{
+				{"[group-header]{{Orders$Category}}", "{{Orders$Price:count}}"},
+				{"[group-by:Orders$Category]{{Orders$Name}}", "{{Orders$Price}}"},
+				{"[group-footer]Subtotal", "{{Orders$Price:sum}}"},
+			},
+			want: "A|2\na1|1.5\na2|2.25\nSubtotal|3.75\nB|1\nb1|10\nSubtotal|10\nC|2\nc1|0.1\nc2|0.2\nSubtotal|0.3",
+		},
+		{
+			name: "grand total",
+			rows: [][]string{
+				{"{{Orders$Name}}", "{{Orders$Qty}}"},
+				{"[grand-total]Total {{Title}}", "{{Orders$Qty:sum}} / {{ Orders$Qty : count }}"},
+			},
+			want: "a1|2\na2|1\nb1|3\nc1|1\nc2|5\nTotal T|12 / 5",
+		},
+		{
+			name: "min max avg",
+			rows: [][]string{
+				{"[group-by:Orders$Category]{{Orders$Name}}", "{{Orders$Qty}}"},
+				{"[group-footer]{{Orders$Qty:min}}-{{Orders$Qty:max}}", "{{Orders$Price:avg}}"},
+			},
+			want: "a1|2\na2|1\n1-2|1.875\nb1|3\n3-3|10\nc1|1\nc2|5\n1-5|0.15",
+		},
+		{
+			name: "empty array",
+			rows: [][]string{
+				{"[group-header]{{None$Category}}", ""},
+				{"[group-by:None$Category]{{None$Name}}", "{{None$Qty}}"},
+				{"[grand-total]Total", "{{None$Qty:sum}}"},
+			},
+			want: "Total|0",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, testTable(test.rows...), testOrdersData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestAggregateValues(t *testing.T) {
+	values := []interface{}{1, 2.5, "3,25", "x", nil, int64(4)}
+	tests := []struct {
+		function string
+		values   []interface{}
+		want     string
+	}{
+		{"sum", values, "10.75"},
+		{"count", values, "6"},
+		{"min", values, "1"},
+		{"max", values, "4"},
+		{"avg", values, "2.6875"},
+		{"sum", nil, "0"},
+		{"min", nil, ""},
+		{"avg", []interface{}{1, 2}, "1.5"},
+		{"sum", []interface{}{0.1, 0.2}, "0.3"},
+		{"sum", []interface{}{-1.5, 1.5}, "0"},
+	}
+	for _, test := range tests {
+		if result := aggregateValues(test.values, test.function); result != test.want {
+			t.Errorf("%s(%v) = %q, want %q", test.function, test.values, result, test.want)
+		}
+	}
+}
+
+func TestGroupLines(t *testing.T) {
+	lines := []map[string]interface{}{
+		{"Category": "A"}, {"Category": "A"}, {"Category": "B"}, {"Category": "A"},
+	}
+	tests := []struct {
+		field string
+		want  [][2]int
+	}{
+		{"Orders$Category", [][2]int{{0, 2}, {2, 3}, {3, 4}}},
+		{"", [][2]int{{0, 4}}},
+	}
+	for _, test := range tests {
+		result := groupLines(lines, test.field)
+		if len(result) != len(test.want) {
+			t.Errorf("groupLines(%q) = %v, want %v", test.field, result, test.want)
+			continue
+		}
+		for index := range result {
+			if result[index] != test.want[index] {
+				t.Errorf("groupLines(%q) = %v, want %v", test.field, result, test.want)
+				break
+			}
+		}
+	}
+}
@@ -6,16 +6,58 @@ import (
 	"io"
 )
 
-// Header - разметка заголовка DOCX
+// Header - разметка заголовка DOCX (w:hdr), подвала (w:ftr)
+// или части со сносками и комментариями (w:footnotes, w:endnotes, w:comments)
 type Header struct {
 	Scheme     map[string]string
 	SkipScheme string
 	Items      []DocItem
+	root       string
+	attrs      []xml.Attr
+}
+
+// HeaderType - тип колонтитула секции (w:type у headerReference/footerReference)
+type HeaderType string
+
+const (
+	// HeaderDefault - колонтитул по умолчанию
+	HeaderDefault HeaderType = "default"
+	// HeaderFirst - колонтитул первой страницы секции
+	HeaderFirst HeaderType = "first"
+	// HeaderEven - колонтитул четных страниц
+	HeaderEven HeaderType = "even"
+)
+
+// HeaderPart - колонтитул, на который ссылается секция документа
+type HeaderPart struct {
+	Name    string
+	Section int
+	Type    HeaderType
+	Footer  bool
+}
+
+// Clone (Header) - клонирование разметки
+func (h *Header) Clone() *Header {
+	result := new(Header)
+	result.Scheme = make(map[string]string)
+	for key, val := range h.Scheme {
+		result.Scheme[key] = val
+	}
+	result.SkipScheme = h.SkipScheme
+	result.root = h.root
+	result.attrs = append([]xml.Attr(nil), h.attrs...)
+	result.Items = make([]DocItem, 0)
+	for _, item := range h.Items {
+		if item != nil {
+			result.Items = append(result.Items, item.Clone())
+		}
+	}
+	return result
 }
 
 /* ДЕКОДИРОВАНИЕ */
 
-// Decode (Document) - декодирование документа
+// Decode (Header) - декодирование заголовка, подвала или сносок
 func (h *Header) Decode(reader io.Reader) error {
 	decoder := xml.NewDecoder(reader)
 	if decoder != nil {
@@ -29,14 +71,10 @@ func (h *Header) Decode(reader io.Reader) error {
 			switch element := token.(type) {
 			case xml.StartElement:
 				{
-					if element.Name.Local == "hdr" {
-						for _, attr := range element.Attr {
-							if attr.Name.Local == "Ignorable" {
-								h.SkipScheme = attr.Value
-							} else {
-								h.Scheme[attr.Name.Local] = attr.Value
-							}
-						}
+					// Первый элемент - корень части (w:hdr, w:ftr, w:footnotes, ...)
+					if len(h.root) == 0 {
+						h.root = element.Name.Local
+						h.SkipScheme, h.attrs = decodeRootAttrs(&element, h.Scheme)
 					} else {
 						item := decodeItem(&element, decoder)
 						if item != nil {
@@ -55,17 +93,15 @@ func (h *Header) Decode(reader io.Reader) error {
 
 // Encode - кодирование
 func (h *Header) Encode(writer io.Writer) error {
-	encoder := xml.NewEncoder(writer)
+	encoder := newXMLEncoder(writer)
 	if encoder != nil {
 		// Начало документа
-		var attrs = make([]xml.Attr, 0)
-		for key, val := range h.Scheme {
-			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + key}, Value: val})
-		}
-		if len(h.SkipScheme) > 0 {
-			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "mc:Ignorable"}, Value: h.SkipScheme})
+		attrs := encodeRootAttrs(h.Scheme, h.SkipScheme, h.attrs)
+		root := h.root
+		if len(root) == 0 {
+			root = "hdr"
 		}
-		hStart := xml.StartElement{Name: xml.Name{Local: "w:hdr"}, Attr: attrs}
+		hStart := xml.StartElement{Name: xml.Name{Space: nsW, Local: root}, Attr: attrs}
 		err := encoder.EncodeToken(hStart)
 		if err != nil {
 			return err
@@ -0,0 +1,348 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aymerick/raymond"
+)
+
+var (
+	rxSortBy = regexp.MustCompile(`\[\s?sort-by\s?:\s?(-?[\w\.\$]+)\s?\]`)
+	rxFilter = regexp.MustCompile(`\[\s?filter\s?:\s?([\w\.\$]+)\s?(?:(!?=)\s?([^\]]*?)\s?)?\]`)
+	// rxQuotes - типографские кавычки, которые Word подставляет вместо "
+	rxQuotes = regexp.MustCompile(`[“”„«»]`)
+)
+
+// helpers - встроенные помощники шаблонов
+// {{sum Items "Amount"}}, {{count Items}}, {{#each (sortBy Items "-Date")}}, {{#each (filter Items "Paid" true)}}
+var helpers = map[string]interface{}{
+	"sum":    helperAggregate("sum"),
+	"avg":    helperAggregate("avg"),
+	"min":    helperAggregate("min"),
+	"max":    helperAggregate("max"),
+	"count":  helperCount,
+	"sortBy": helperSortBy,
+	"filter": helperFilter,
+}
+
+// helperAggregate - помощник итога по полю элементов массива
+func helperAggregate(function string) func(items interface{}, field string) string {
+	return func(items interface{}, field string) string {
+		elements := blockElements(items)
+		values := make([]interface{}, 0, len(elements))
+		for _, element := range elements {
+			value, _ := lookupValue(element, field)
+			values = append(values, value)
+		}
+		return aggregateValues(values, function)
+	}
+}
+
+// helperCount - количество элементов массива
+func helperCount(items interface{}) int {
+	return len(blockElements(items))
+}
+
+// helperSortBy - элементы массива, отсортированные по полю (-поле - по убыванию)
+func helperSortBy(items interface{}, field string) []interface{} {
+	elements := append([]interface{}(nil), blockElements(items)...)
+	sortElements(elements, []string{field})
+	return elements
+}
+
+// helperFilter - элементы массива, у которых значение поля совпадает с value
+func helperFilter(items interface{}, field string, value interface{}) []interface{} {
+	result := make([]interface{}, 0)
+	for _, element := range blockElements(items) {
+		if fieldValue, _ := lookupValue(element, field); raymond.Str(fieldValue) == raymond.Str(value) {
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+// sortElements - устойчивая сортировка элементов по полям (-поле - по убыванию)
+func sortElements(elements []interface{}, fields []string) {
+	sort.SliceStable(elements, func(i, j int) bool {
+		for _, field := range fields {
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimPrefix(field, "-")
+			a, _ := lookupValue(elements[i], field)
+			b, _ := lookupValue(elements[j], field)
+			if result := compareValues(a, b); result != 0 {
+				return (result < 0) != desc
+			}
+		}
+		return false
+	})
+}
+
+// compareValues - сравнение значений: числа и даты по значению, остальное как текст
+func compareValues(a, b interface{}) int {
+	if x, _, ok := toNumber(a); ok {
+		if y, _, ok := toNumber(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(raymond.Str(a), raymond.Str(b))
+}
+
+// RegisterHelper (SimpleDocxFile) - регистрация помощника шаблона (функции с одним результатом, как для raymond.RegisterHelper)
+// Помощники шаблона имеют приоритет над встроенными и общими, общий список помощников raymond не меняется
+func (f *SimpleDocxFile) RegisterHelper(name string, helper interface{}) error {
+	fn := reflect.ValueOf(helper)
+	if fn.Kind() != reflect.Func {
+		return errors.New("Helper must be a function: " + name)
+	}
+	// raymond проверяет помощники при рендере и завершает его паникой
+	if fn.Type().NumOut() != 1 {
+		return errors.New("Helper must return one value: " + name)
+	}
+	if f.helpers == nil {
+		f.helpers = make(map[string]interface{})
+	}
+	f.helpers[name] = helper
+	return nil
+}
+
+// mergeHelpers - встроенные помощники, помощники языка и помощники шаблона
+// Помощники рендера зарегистрированы в шаблоне raymond, поэтому встроенный помощник скрывает общий помощник
+// с тем же именем; чтобы вызывать общий помощник, его регистрируют в шаблоне (RegisterHelper)
+func mergeHelpers(l *localeFormat, local map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(helpers)+len(local)+8)
+	for _, list := range []map[string]interface{}{helpers, localeHelpers(l), local} {
+		for name, helper := range list {
+			result[name] = helper
+		}
+	}
+	return result
+}
+
+// renderText - рендер текста записи с помощниками шаблона
+func (r *renderer) renderText(text string, v interface{}) (string, error) {
+	tpl, err := raymond.Parse(text)
+	if err != nil {
+		return "", err
+	}
+	tpl.RegisterHelpers(r.helpers)
+	return tpl.Exec(v)
+}
+
+// evalExpression - значение выражения блока: путь к данным или вызов помощника ((sortBy Items "Date"))
+func (r *renderer) evalExpression(v interface{}, expr string) (interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "(") {
+		value, _ := lookupValue(v, expr)
+		return value, nil
+	}
+	if !strings.HasSuffix(expr, ")") {
+		return nil, errors.New("Not valid expression " + expr)
+	}
+	tokens, err := splitExpression(rxQuotes.ReplaceAllString(expr[1:len(expr)-1], `"`))
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("Not valid expression " + expr)
+	}
+	// общие помощники raymond недоступны вне его шаблонов
+	helper, ok := r.helpers[tokens[0]]
+	if !ok {
+		return nil, errors.New("Unknown helper " + tokens[0] + ", global helpers must be registered on the template")
+	}
+	fn := reflect.ValueOf(helper)
+	params := fn.Type().NumIn()
+	// последний параметр *raymond.Options заполняется пустыми опциями
+	withOptions := params > 0 && fn.Type().In(params-1) == optionsType
+	if withOptions {
+		params--
+	}
+	if params != len(tokens)-1 {
+		return nil, errors.New("Helper " + tokens[0] + " called with wrong number of arguments")
+	}
+	args := make([]reflect.Value, 0, len(tokens))
+	for index, token := range tokens[1:] {
+		value, err := r.evalArgument(v, token)
+		if err != nil {
+			return nil, err
+		}
+		argType := fn.Type().In(index)
+		arg := reflect.ValueOf(value)
+		switch {
+		case !arg.IsValid():
+			arg = reflect.Zero(argType)
+		case arg.Type().AssignableTo(argType):
+		case argType.Kind() == reflect.String:
+			arg = reflect.ValueOf(raymond.Str(value)).Convert(argType)
+		case argType.Kind() == reflect.Bool:
+			arg = reflect.ValueOf(raymond.IsTrue(value))
+		default:
+			return nil, errors.New("Helper " + tokens[0] + " called with wrong argument " + token)
+		}
+		args = append(args, arg)
+	}
+	if withOptions {
+		args = append(args, reflect.ValueOf(new(raymond.Options)))
+	}
+	return callHelper(tokens[0], fn, args)
+}
+
+// optionsType - тип последнего необязательного параметра помощника
+var optionsType = reflect.TypeOf((*raymond.Options)(nil))
+
+// callHelper - вызов помощника, паника помощника возвращается как ошибка
+func callHelper(name string, fn reflect.Value, args []reflect.Value) (result interface{}, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = errors.New("Helper " + name + ": " + fmt.Sprint(rec))
+		}
+	}()
+	results := fn.Call(args)
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return results[0].Interface(), nil
+}
+
+// evalArgument - значение аргумента помощника: строка в кавычках, число, true/false, вложенное выражение или путь к данным
+func (r *renderer) evalArgument(v interface{}, token string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(token, "("):
+		return r.evalExpression(v, token)
+	case strings.HasPrefix(token, `"`) || strings.HasPrefix(token, "'"):
+		return token[1 : len(token)-1], nil
+	case token == "true" || token == "false":
+		return token == "true", nil
+	}
+	if number, err := strconv.ParseInt(token, 10, 64); err == nil {
+		return int(number), nil
+	}
+	if number, err := strconv.ParseFloat(token, 64); err == nil {
+		return number, nil
+	}
+	value, _ := lookupValue(v, token)
+	return value, nil
+}
+
+// splitExpression - деление выражения на слова с учетом кавычек и скобок
+func splitExpression(expr string) ([]string, error) {
+	tokens := make([]string, 0)
+	start, depth := -1, 0
+	var quote rune
+	for index, char := range expr + " " {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+			continue
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '(':
+			depth++
+		case char == ')':
+			depth--
+		case (char == ' ' || char == '\t') && depth == 0:
+			if start >= 0 {
+				tokens = append(tokens, expr[start:index])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = index
+		}
+	}
+	if quote != 0 || depth != 0 {
+		return nil, errors.New("Not valid expression " + expr)
+	}
+	return tokens, nil
+}
+
+// rowsSelection - сортировка [sort-by:Items$Field] и отбор [filter:Items$Field=значение] строк массива
+type rowsSelection struct {
+	sorts   []string
+	filters [][]string
+}
+
+// newRowsSelection - флаги сортировки и отбора в строках группы (флаги удаляются)
+func newRowsSelection(group []*TableRow) *rowsSelection {
+	result := new(rowsSelection)
+	for _, row := range group {
+		for _, cell := range row.Cells {
+			text := plainTextFromTableCell(cell)
+			for _, match := range rxSortBy.FindAllStringSubmatch(text, -1) {
+				result.sorts = append(result.sorts, match[1])
+			}
+			for _, match := range rxFilter.FindAllStringSubmatch(text, -1) {
+				result.filters = append(result.filters, match[1:])
+			}
+			removeTemplateFromCell(rxSortBy, cell)
+			removeTemplateFromCell(rxFilter, cell)
+		}
+	}
+	return result
+}
+
+// apply (rowsSelection) - отбор и сортировка строк массива
+// [filter:Items$Field] оставляет строки с истинным значением поля, [filter:Items$Field!=значение] - с другим значением
+func (s *rowsSelection) apply(lines []map[string]interface{}) []map[string]interface{} {
+	if len(s.filters) > 0 {
+		result := make([]map[string]interface{}, 0, len(lines))
+		for index := range lines {
+			keep := true
+			for _, filter := range s.filters {
+				value, _ := lookupValue(&lines[index], filter[0])
+				switch filter[1] {
+				case "=":
+					keep = keep && raymond.Str(value) == filter[2]
+				case "!=":
+					keep = keep && raymond.Str(value) != filter[2]
+				default:
+					keep = keep && raymond.IsTrue(value)
+				}
+			}
+			if keep {
+				result = append(result, lines[index])
+			}
+		}
+		lines = result
+	}
+	if len(s.sorts) > 0 {
+		elements := make([]interface{}, len(lines))
+		for index := range lines {
+			elements[index] = &lines[index]
+		}
+		sortElements(elements, s.sorts)
+		result := make([]map[string]interface{}, len(lines))
+		for index, element := range elements {
+			result[index] = *element.(*map[string]interface{})
+		}
+		lines = result
+	}
+	return lines
+}
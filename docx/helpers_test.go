@@ -0,0 +1,246 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aymerick/raymond"
+)
+
+func TestCollectionHelpers(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"sum", testParagraph(`{{sum Orders "Price"}}`), "14.05"},
+		{"avg smart quotes", testParagraph(`{{avg Orders “Price”}}`), "2.81"},
+		{"min max", testParagraph(`{{min Orders "Qty"}}-{{max Orders "Qty"}}`), "1-5"},
+		{"count", testParagraph(`{{count Orders}} {{count None}}`), "5 0"},
+		{"sortBy", testParagraphs(`{{#each (sortBy Orders "-Price")}}`, "{{Name}}", "{{/each}}"), "b1\na2\na1\nc2\nc1"},
+		{"filter", testParagraph(`{{#each (filter Orders "Category" "A")}}{{Name}};{{/each}}`), "a1;a2;"},
+		{"nested", testParagraphs(`{{#each (sortBy (filter Orders "Category" "C") "-Name")}}`, "{{Name}}", "{{/each}}"), "c2\nc1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, test.body, testOrdersData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestRowsSelection(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		want string
+	}{
+		{"sort", [][]string{{"[sort-by:Orders$Qty]{{Orders$Name}}", "{{Orders$Qty}}"}}, "a2|1\nc1|1\na1|2\nb1|3\nc2|5"},
+		{"sort desc", [][]string{{"[sort-by:-Orders$Price]{{Orders$Name}}"}}, "b1\na2\na1\nc2\nc1"},
+		{"sort by two fields", [][]string{{"[sort-by:Orders$Category][sort-by:-Orders$Qty]{{Orders$Name}}"}}, "a1\na2\nb1\nc2\nc1"},
+		{"filter equal", [][]string{{"[filter:Orders$Category=C]{{Orders$Name}}"}}, "c1\nc2"},
+		{"filter not equal", [][]string{{"[filter:Orders$Category!=A]{{Orders$Name}}"}}, "b1\nc1\nc2"},
+		{"filter and total", [][]string{{"[filter:Orders$Category=A]{{Orders$Name}}", "{{Orders$Price}}"}, {"[grand-total]Total", "{{Orders$Price:sum}}"}}, "a1|1.5\na2|2.25\nTotal|3.75"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, testTable(test.rows...), testOrdersData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+		err  bool
+	}{
+		{`sortBy Items "Date"`, []string{"sortBy", "Items", `"Date"`}, false},
+		{`filter Items "Name" "a b"`, []string{"filter", "Items", `"Name"`, `"a b"`}, false},
+		{`sortBy (filter Items 'Paid' true) "-Date"`, []string{"sortBy", "(filter Items 'Paid' true)", `"-Date"`}, false},
+		{`count  Items `, []string{"count", "Items"}, false},
+		{`sortBy Items "Date`, nil, true},
+		{`sortBy (filter Items`, nil, true},
+	}
+	for _, test := range tests {
+		result, err := splitExpression(test.expr)
+		if (err != nil) != test.err {
+			t.Errorf("splitExpression(%q) error %v", test.expr, err)
+			continue
+		}
+		if !test.err && !reflect.DeepEqual(result, test.want) {
+			t.Errorf("splitExpression(%q) = %q, want %q", test.expr, result, test.want)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want int
+	}{
+		{2, 10, -1},
+		{"10", 9.5, 1},
+		{"b", "a", 1},
+		{"x", "x", 0},
+		{nil, "a", -1},
+	}
+	for _, test := range tests {
+		if result := compareValues(test.a, test.b); result != test.want {
+			t.Errorf("compareValues(%v, %v) = %d, want %d", test.a, test.b, result, test.want)
+		}
+	}
+}
+
+func TestRegisterHelper(t *testing.T) {
+	tests := []struct {
+		name   string
+		helper interface{}
+		err    bool
+	}{
+		{"upper", strings.ToUpper, false},
+		{"options", func(value string, options *raymond.Options) string { return value + options.HashStr("suffix") }, false},
+		{"not function", "upper", true},
+		{"two results", func(value string) (string, error) { return value, nil }, true},
+		{"no result", func(value string) {}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, testParagraph("{{"+test.name+" Title}}"))
+			err := f.RegisterHelper(test.name, test.helper)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if err := f.Render(testOrdersData); err != nil {
+				t.Fatal(err)
+			}
+			if result := testText(t, f); result != "T" {
+				t.Errorf("got %q, want %q", result, "T")
+			}
+		})
+	}
+}
+
+func TestBlockHelperOptions(t *testing.T) {
+	helpers := map[string]interface{}{
+		"first": func(items interface{}, options *raymond.Options) []interface{} {
+			elements := blockElements(items)
+			if len(elements) > 2 && options.HashStr("all") == "" {
+				elements = elements[:2]
+			}
+			return elements
+		},
+		"block": func(items interface{}, options *raymond.Options) string { return options.Fn() },
+	}
+	tests := []struct {
+		name string
+		body string
+		want string
+		err  bool
+	}{
+		{"options", testParagraphs(`{{#each (first Orders)}}`, "{{Name}}", "{{/each}}"), "a1\na2", false},
+		{"nested", testParagraphs(`{{#each (first (sortBy Orders "-Price"))}}`, "{{Name}}", "{{/each}}"), "b1\na2", false},
+		{"wrong number of arguments", testParagraphs(`{{#each (first Orders "Name")}}`, "{{Name}}", "{{/each}}"), "", true},
+		{"panic", testParagraphs(`{{#each (block Orders)}}`, "{{Name}}", "{{/each}}"), "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, test.body)
+			for name, helper := range helpers {
+				if err := f.RegisterHelper(name, helper); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := f.Render(testOrdersData)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if result := testText(t, f); result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+// testGlobalCalls - число вызовов общих помощников raymond из TestGlobalHelpers
+var testGlobalCalls int32
+
+// testGlobalOnce - общий помощник raymond регистрируется один раз (удалить его нельзя)
+var testGlobalOnce sync.Once
+
+func TestGlobalHelpers(t *testing.T) {
+	// общий помощник, который падает на неверных данных
+	testGlobalOnce.Do(func() {
+		global := func(value interface{}) string {
+			atomic.AddInt32(&testGlobalCalls, 1)
+			if _, ok := value.(string); !ok {
+				panic("testGlobal: bad argument")
+			}
+			return "global"
+		}
+		raymond.RegisterHelper("testBuiltin", global)
+		raymond.RegisterHelper("testGlobal", global)
+	})
+	// встроенный помощник добавляется только на время теста
+	helpers["testBuiltin"] = func(value interface{}) string { return "builtin" }
+	defer delete(helpers, "testBuiltin")
+
+	tests := []struct {
+		name  string
+		body  string
+		local bool
+		want  string
+		calls int32
+		err   bool
+	}{
+		{"builtin", testParagraph("{{testBuiltin Title}}"), false, "builtin", 0, false},
+		{"template", testParagraph("{{testBuiltin Title}}"), true, "template", 0, false},
+		{"global", testParagraph("{{testGlobal Title}}"), false, "global", 1, false},
+		{"unused global", testParagraph("{{Title}}"), false, "T", 0, false},
+		{"block expression", testParagraphs("{{#each (testGlobal Orders)}}", "{{Name}}", "{{/each}}"), false, "", 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			atomic.StoreInt32(&testGlobalCalls, 0)
+			f := testOpen(t, test.body)
+			if test.local {
+				if err := f.RegisterHelper("testBuiltin", func(value interface{}) string { return "template" }); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := f.Render(testOrdersData)
+			if calls := atomic.LoadInt32(&testGlobalCalls); calls != test.calls {
+				t.Errorf("global helper called %d times, want %d", calls, test.calls)
+			}
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if result := testText(t, f); result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package docx
+
+// RowHook - обработчик строки таблицы, выведенной по элементу массива
+// item - данные строки, index - номер строки данных
+// Ключи item - пути шаблона без первого имени, $ заменяется на _: {{Items$Name}} - Name, {{Doc$Items$Name}} - Items_Name
+type RowHook func(row *TableRow, item map[string]interface{}, index int)
+
+// CellHook - обработчик ячейки строки таблицы, выведенной по элементу массива
+// column - номер ячейки в строке
+type CellHook func(cell *TableCell, item map[string]interface{}, index int, column int)
+
+// OnRow (SimpleDocxFile) - обработчик, вызываемый после рендера каждой строки из массива
+// Для группы строк [rows:N] вызывается для каждой строки группы с одними данными
+func (f *SimpleDocxFile) OnRow(hook RowHook) {
+	if hook != nil {
+		f.rowHooks = append(f.rowHooks, hook)
+	}
+}
+
+// OnCell (SimpleDocxFile) - обработчик, вызываемый после рендера каждой ячейки строки из массива
+func (f *SimpleDocxFile) OnCell(hook CellHook) {
+	if hook != nil {
+		f.cellHooks = append(f.cellHooks, hook)
+	}
+}
+
+// callHooks - вызов обработчиков строки и ее ячеек
+func (r *renderer) callHooks(row *TableRow, item map[string]interface{}, index int) {
+	for _, hook := range r.rowHooks {
+		hook(row, item, index)
+	}
+	if len(r.cellHooks) > 0 {
+		for column, cell := range row.Cells {
+			for _, hook := range r.cellHooks {
+				hook(cell, item, index, column)
+			}
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package docx
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aymerick/raymond"
+)
+
+// localeFormat - правила записи чисел, сумм и дат для языка
+type localeFormat struct {
+	// decimal, group - разделитель дробной части и групп разрядов
+	decimal string
+	group   string
+	// currency, percent - запись суммы и процентов, %s - число
+	currency         string
+	currencyDecimals int
+	percent          string
+	// short, long - формат даты по умолчанию и длинный формат
+	short string
+	long  string
+	// months - названия месяцев для длинного формата (January в формате заменяется)
+	months []string
+	// words - число прописью, amount - сумма прописью (целая часть и копейки), minus - знак прописью
+	words  func(n int64) string
+	amount func(whole, minor int64) string
+	minus  string
+}
+
+// locales - поддерживаемые языки
+var locales = map[string]*localeFormat{
+	"en": {decimal: ".", group: ",", currency: "$%s", currencyDecimals: 2, percent: "%s%%",
+		short: "01/02/2006", long: "January 2, 2006", words: wordsEn, amount: amountEn, minus: "minus"},
+	"ru": {decimal: ",", group: "\u00a0", currency: "%s\u00a0₽", currencyDecimals: 2, percent: "%s\u00a0%%",
+		short: "02.01.2006", long: "2 January 2006 г.", months: monthsRu, words: wordsRu, amount: amountRu, minus: "минус"},
+	"vi": {decimal: ",", group: ".", currency: "%s\u00a0₫", currencyDecimals: 0, percent: "%s%%",
+		short: "02/01/2006", long: "ngày 2 tháng 1 năm 2006", words: wordsVi, amount: amountVi, minus: "âm"},
+}
+
+// findLocale - правила языка по имени (ru, ru-RU, vi_VN), по умолчанию en
+func findLocale(name string) *localeFormat {
+	name = strings.ToLower(name)
+	if index := strings.IndexAny(name, "-_"); index >= 0 {
+		name = name[:index]
+	}
+	if l, ok := locales[name]; ok {
+		return l
+	}
+	return locales["en"]
+}
+
+// localeHelpers - помощники форматирования для языка
+// {{formatNumber Amount decimals=2}}, {{currency Amount}}, {{percent Rate}}, {{date Date layout="long"}},
+// {{inWords Count}}, {{amountInWords Amount}}
+func localeHelpers(l *localeFormat) map[string]interface{} {
+	return map[string]interface{}{
+		"formatNumber": func(value interface{}, options *raymond.Options) string {
+			number, digits, ok := toNumber(value)
+			if !ok {
+				return raymond.Str(value)
+			}
+			return l.formatNumber(number, hashDecimals(options, digits))
+		},
+		"currency": func(value interface{}, options *raymond.Options) string {
+			number, _, ok := toNumber(value)
+			if !ok {
+				return raymond.Str(value)
+			}
+			// Знак ставится перед записью суммы: -$22, -22 ₽
+			text := l.formatNumber(number, hashDecimals(options, l.currencyDecimals))
+			if strings.HasPrefix(text, "-") {
+				return "-" + fmt.Sprintf(l.currency, text[1:])
+			}
+			return fmt.Sprintf(l.currency, text)
+		},
+		"percent": func(value interface{}, options *raymond.Options) string {
+			number, digits, ok := toNumber(value)
+			if !ok {
+				return raymond.Str(value)
+			}
+			decimals := digits - 2
+			if decimals < 0 {
+				decimals = 0
+			}
+			return fmt.Sprintf(l.percent, l.formatNumber(number*100, hashDecimals(options, decimals)))
+		},
+		"date": func(value interface{}, options *raymond.Options) string {
+			t, ok := toTime(value)
+			if !ok {
+				return raymond.Str(value)
+			}
+			return l.formatDate(t, options.HashStr("layout"))
+		},
+		"inWords": func(value interface{}) string {
+			number, _, ok := toNumber(value)
+			if !ok {
+				return raymond.Str(value)
+			}
+			return l.words(int64(number))
+		},
+		"amountInWords": func(value interface{}) string {
+			number, _, ok := toNumber(value)
+			if !ok {
+				return raymond.Str(value)
+			}
+			cents := int64(math.Round(math.Abs(number) * 100))
+			text := l.amount(cents/100, cents%100)
+			if number < 0 && cents > 0 {
+				text = l.minus + " " + text
+			}
+			return text
+		},
+	}
+}
+
+// hashDecimals - количество знаков после запятой из параметра decimals=N помощника
+func hashDecimals(options *raymond.Options, defaults int) int {
+	if number, _, ok := toNumber(options.HashProp("decimals")); ok && number >= 0 {
+		return int(number)
+	}
+	return defaults
+}
+
+// formatNumber (localeFormat) - число с разделителями разрядов и decimals знаками после запятой
+func (l *localeFormat) formatNumber(number float64, decimals int) string {
+	text := strconv.FormatFloat(math.Abs(number), 'f', decimals, 64)
+	whole, fraction := text, ""
+	if index := strings.IndexByte(text, '.'); index >= 0 {
+		whole, fraction = text[:index], text[index+1:]
+	}
+	groups := make([]string, 0, len(whole)/3+1)
+	for len(whole) > 3 {
+		groups = append([]string{whole[len(whole)-3:]}, groups...)
+		whole = whole[:len(whole)-3]
+	}
+	text = strings.Join(append([]string{whole}, groups...), l.group)
+	if len(fraction) > 0 {
+		text += l.decimal + fraction
+	}
+	if number < 0 && strings.Trim(text, "0"+l.decimal+l.group) != "" {
+		text = "-" + text
+	}
+	return text
+}
+
+// formatDate (localeFormat) - дата в формате: short (по умолчанию), long или формат Go (02.01.2006)
+func (l *localeFormat) formatDate(t time.Time, layout string) string {
+	switch strings.TrimSpace(layout) {
+	case "", "short":
+		layout = l.short
+	case "long":
+		layout = l.long
+	}
+	text := t.Format(layout)
+	if l.months != nil && strings.Contains(layout, "January") {
+		text = strings.Replace(text, t.Month().String(), l.months[t.Month()-1], -1)
+	}
+	return text
+}
+
+// toTime - значение даты: time.Time или строка в формате RFC3339 (2006-01-02)
+func toTime(v interface{}) (time.Time, bool) {
+	switch value := v.(type) {
+	case time.Time:
+		return value, !value.IsZero()
+	case *time.Time:
+		if value != nil {
+			return *value, !value.IsZero()
+		}
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// triads - деление числа на группы по три разряда, начиная со старшей
+func triads(n uint64) []int {
+	result := make([]int, 0, 7)
+	for n > 0 {
+		result = append([]int{int(n % 1000)}, result...)
+		n /= 1000
+	}
+	return result
+}
+
+// splitSign - модуль числа и слово для отрицательного числа
+func splitSign(n int64, minus string) (uint64, string) {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1, minus + " "
+	}
+	return uint64(n), ""
+}
+
+/* РУССКИЙ */
+
+var (
+	monthsRu = []string{"января", "февраля", "марта", "апреля", "мая", "июня",
+		"июля", "августа", "сентября", "октября", "ноября", "декабря"}
+	unitsRu    = []string{"", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	unitsFemRu = []string{"", "одна", "две"}
+	teensRu    = []string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать",
+		"шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	tensRu     = []string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	hundredsRu = []string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+	// scalesRu - разряды: формы для 1, 2-4, 5 и женский род
+	scalesRu = [][4]string{{"", "", "", ""}, {"тысяча", "тысячи", "тысяч", "f"}, {"миллион", "миллиона", "миллионов", ""},
+		{"миллиард", "миллиарда", "миллиардов", ""}, {"триллион", "триллиона", "триллионов", ""},
+		{"квадриллион", "квадриллиона", "квадриллионов", ""}, {"квинтиллион", "квинтиллиона", "квинтиллионов", ""}}
+)
+
+// pluralRu - форма слова для числа: 1 рубль, 2 рубля, 5 рублей
+func pluralRu(n int64, one, few, many string) string {
+	n %= 100
+	switch {
+	case n >= 11 && n <= 14:
+		return many
+	case n%10 == 1:
+		return one
+	case n%10 >= 2 && n%10 <= 4:
+		return few
+	}
+	return many
+}
+
+// triadRu - три разряда прописью
+func triadRu(n int, feminine bool) []string {
+	words := make([]string, 0, 3)
+	if n/100 > 0 {
+		words = append(words, hundredsRu[n/100])
+	}
+	switch tens, units := n/10%10, n%10; {
+	case tens == 1:
+		words = append(words, teensRu[units])
+	case tens > 1:
+		words = append(words, tensRu[tens])
+		fallthrough
+	default:
+		if feminine && units > 0 && units < 3 {
+			words = append(words, unitsFemRu[units])
+		} else if units > 0 {
+			words = append(words, unitsRu[units])
+		}
+	}
+	return words
+}
+
+func wordsRu(n int64) string {
+	if n == 0 {
+		return "ноль"
+	}
+	value, sign := splitSign(n, "минус")
+	words := make([]string, 0)
+	groups := triads(value)
+	for index, group := range groups {
+		scale := scalesRu[len(groups)-index-1]
+		if group == 0 {
+			continue
+		}
+		words = append(words, triadRu(group, scale[3] == "f")...)
+		if len(scale[0]) > 0 {
+			words = append(words, pluralRu(int64(group), scale[0], scale[1], scale[2]))
+		}
+	}
+	return sign + strings.Join(words, " ")
+}
+
+func amountRu(whole, minor int64) string {
+	return fmt.Sprintf("%s %s %02d %s", wordsRu(whole), pluralRu(whole, "рубль", "рубля", "рублей"),
+		minor, pluralRu(minor, "копейка", "копейки", "копеек"))
+}
+
+/* ВЬЕТНАМСКИЙ */
+
+var (
+	digitsVi = []string{"không", "một", "hai", "ba", "bốn", "năm", "sáu", "bảy", "tám", "chín"}
+	scalesVi = []string{"", "nghìn", "triệu", "tỷ", "nghìn tỷ", "triệu tỷ", "tỷ tỷ"}
+)
+
+// triadVi - три разряда прописью, full - с нулевыми сотнями (не старшая группа)
+func triadVi(n int, full bool) []string {
+	hundreds, tens, units := n/100, n/10%10, n%10
+	words := make([]string, 0, 5)
+	if hundreds > 0 || full {
+		words = append(words, digitsVi[hundreds], "trăm")
+	}
+	switch {
+	case tens == 0 && units > 0 && len(words) > 0:
+		words = append(words, "lẻ")
+	case tens == 1:
+		words = append(words, "mười")
+	case tens > 1:
+		words = append(words, digitsVi[tens], "mươi")
+	}
+	switch {
+	case units == 0:
+	case units == 1 && tens > 1:
+		words = append(words, "mốt")
+	case units == 5 && tens > 0:
+		words = append(words, "lăm")
+	default:
+		words = append(words, digitsVi[units])
+	}
+	return words
+}
+
+func wordsVi(n int64) string {
+	if n == 0 {
+		return "không"
+	}
+	value, sign := splitSign(n, "âm")
+	words := make([]string, 0)
+	groups := triads(value)
+	for index, group := range groups {
+		if group == 0 {
+			continue
+		}
+		words = append(words, triadVi(group, index > 0)...)
+		if scale := scalesVi[len(groups)-index-1]; len(scale) > 0 {
+			words = append(words, scale)
+		}
+	}
+	return sign + strings.Join(words, " ")
+}
+
+func amountVi(whole, minor int64) string {
+	if minor >= 50 {
+		whole++
+	}
+	return wordsVi(whole) + " đồng"
+}
+
+/* АНГЛИЙСКИЙ */
+
+var (
+	unitsEn = []string{"", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+		"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	tensEn   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	scalesEn = []string{"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion"}
+)
+
+// triadEn - три разряда прописью
+func triadEn(n int) []string {
+	words := make([]string, 0, 3)
+	if n/100 > 0 {
+		words = append(words, unitsEn[n/100], "hundred")
+	}
+	switch rest := n % 100; {
+	case rest == 0:
+	case rest < 20:
+		words = append(words, unitsEn[rest])
+	case rest%10 == 0:
+		words = append(words, tensEn[rest/10])
+	default:
+		words = append(words, tensEn[rest/10]+"-"+unitsEn[rest%10])
+	}
+	return words
+}
+
+func wordsEn(n int64) string {
+	if n == 0 {
+		return "zero"
+	}
+	value, sign := splitSign(n, "minus")
+	words := make([]string, 0)
+	groups := triads(value)
+	for index, group := range groups {
+		if group == 0 {
+			continue
+		}
+		words = append(words, triadEn(group)...)
+		if scale := scalesEn[len(groups)-index-1]; len(scale) > 0 {
+			words = append(words, scale)
+		}
+	}
+	return sign + strings.Join(words, " ")
+}
+
+func amountEn(whole, minor int64) string {
+	dollars, cents := "dollars", "cents"
+	if whole == 1 {
+		dollars = "dollar"
+	}
+	if minor == 1 {
+		cents = "cent"
+	}
+	return fmt.Sprintf("%s %s and %02d %s", wordsEn(whole), dollars, minor, cents)
+}
@@ -0,0 +1,67 @@
+package docx
+
+import (
+	"testing"
+	"time"
+)
+
+type testAmounts struct {
+	Amount, Rate, Neg float64
+	Date              time.Time
+	Text              string
+}
+
+var testAmountsData = &testAmounts{Amount: 1234567.891, Rate: 0.125, Neg: -21.5,
+	Date: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC), Text: "2026-03-08"}
+
+func TestLocaleHelpers(t *testing.T) {
+	tests := []struct {
+		locale string
+		body   string
+		want   string
+	}{
+		{"en", `{{formatNumber Amount}} {{formatNumber Amount decimals=2}}`, "1,234,567.891 1,234,567.89"},
+		{"ru", `{{formatNumber Amount decimals=2}}`, "1\u00a0234\u00a0567,89"},
+		{"vi", `{{formatNumber Amount decimals=0}}`, "1.234.568"},
+		{"en", `{{currency Amount}} {{currency Neg decimals=0}}`, "$1,234,567.89 -$22"},
+		{"ru-RU", `{{currency Amount}}`, "1\u00a0234\u00a0567,89\u00a0₽"},
+		{"vi_VN", `{{currency Amount}}`, "1.234.568\u00a0₫"},
+		{"en", `{{percent Rate}} {{percent Rate decimals=2}}`, "12.5% 12.50%"},
+		{"ru", `{{percent Rate}}`, "12,5\u00a0%"},
+		{"en", `{{date Date}}|{{date Date layout="short"}}|{{date Date layout="long"}}`, "03/08/2026|03/08/2026|March 8, 2026"},
+		{"ru", `{{date Date}}|{{date Date layout="long"}}`, "08.03.2026|8 марта 2026 г."},
+		{"vi", `{{date Date}}|{{date Date layout="long"}}`, "08/03/2026|ngày 8 tháng 3 năm 2026"},
+		{"en", `{{date Date layout="2006-01-02"}} {{date Text layout="02.01.2006"}}`, "2026-03-08 08.03.2026"},
+		{"xx", `{{date Date}}`, "03/08/2026"},
+		{"en", `{{inWords 21}} {{amountInWords 1.01}}`, "twenty-one one dollar and 01 cent"},
+		{"ru", `{{inWords 21}}`, "двадцать один"},
+		{"vi", `{{inWords 21}}`, "hai mươi mốt"},
+	}
+	for _, test := range tests {
+		t.Run(test.locale+" "+test.body, func(t *testing.T) {
+			result, err := testRender(t, testParagraph(test.body), testAmountsData, Locale(test.locale))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestLocaleHelpersInRows(t *testing.T) {
+	data := struct{ Items []testAmounts }{Items: []testAmounts{
+		{Amount: 1500, Rate: 0.2, Date: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Text: "a"},
+		{Amount: 20.5, Rate: 0.05, Date: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), Text: "b"},
+	}}
+	body := testTable([]string{"{{Items$Text}}", "{{currency Items$Amount}}", `{{date Items$Date layout="long"}}`, "{{percent Items$Rate decimals=1}}"})
+	result, err := testRender(t, body, data, Locale("ru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a|1\u00a0500,00\u00a0₽|5 января 2026 г.|20,0\u00a0%\nb|20,50\u00a0₽|31 декабря 2026 г.|5,0\u00a0%"
+	if result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
@@ -0,0 +1,149 @@
+package docx
+
+// Виды частей документа для параметров рендера
+const (
+	PartDocument  = "document"
+	PartHeader    = "header"
+	PartFooter    = "footer"
+	PartFootnotes = "footnotes"
+	PartEndnotes  = "endnotes"
+	PartComments  = "comments"
+)
+
+// MergeSeparator - разделитель копий документа при слиянии (RenderMerge)
+type MergeSeparator int
+
+const (
+	// MergePageBreak - копии разделяются разрывом страницы
+	MergePageBreak MergeSeparator = iota
+	// MergeSection - каждая копия в своей секции с параметрами секции шаблона
+	MergeSection
+)
+
+// EmptyArrayMode - вывод строк таблицы для пустого массива
+type EmptyArrayMode int
+
+const (
+	// EmptyArrayRemove - строки шаблона удаляются (по умолчанию)
+	EmptyArrayRemove EmptyArrayMode = iota
+	// EmptyArrayFallback - выводится строка [no-data], следующая за строками шаблона,
+	// без нее - строки шаблона с пустыми значениями
+	EmptyArrayFallback
+	// EmptyArrayError - рендер завершается ошибкой
+	EmptyArrayError
+)
+
+// OpenOption - параметр открытия файла DOCX
+type OpenOption func(o *openOptions)
+
+// openOptions - параметры открытия файла DOCX
+type openOptions struct {
+	inMemory      bool
+	deterministic bool
+}
+
+func newOpenOptions(opts []OpenOption) *openOptions {
+	o := new(openOptions)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// InMemory - при открытии архив целиком загружается в память,
+// после этого исходный файл не нужен (его можно перемещать и удалять)
+func InMemory() OpenOption {
+	return func(o *openOptions) {
+		o.inMemory = true
+	}
+}
+
+// Deterministic - побайтно одинаковый результат для одного шаблона и данных:
+// у всех частей архива фиксированное время изменения
+// (объявления пространств имен и атрибуты всегда записываются в постоянном порядке)
+func Deterministic() OpenOption {
+	return func(o *openOptions) {
+		o.deterministic = true
+	}
+}
+
+// RenderOption - параметр рендера шаблона
+type RenderOption func(o *renderOptions)
+
+// renderOptions - параметры рендера шаблона
+type renderOptions struct {
+	skip        map[string]bool
+	data        map[string]interface{}
+	separator   MergeSeparator
+	emptyArrays EmptyArrayMode
+	locale      string
+}
+
+func newRenderOptions(opts []RenderOption) *renderOptions {
+	o := &renderOptions{skip: make(map[string]bool), data: make(map[string]interface{})}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// SkipPart - не рендерить части документа
+// Часть задается именем в пакете (word/header1.xml) или видом (PartHeader, PartFooter, ...)
+func SkipPart(names ...string) RenderOption {
+	return func(o *renderOptions) {
+		for _, name := range names {
+			o.skip[name] = true
+		}
+	}
+}
+
+// PartData - рендерить части документа с другими данными
+// Часть задается именем в пакете (word/header1.xml) или видом (PartHeader, PartFooter, ...)
+func PartData(name string, v interface{}) RenderOption {
+	return func(o *renderOptions) {
+		o.data[name] = v
+	}
+}
+
+// SeparateBy - разделитель копий документа при слиянии (по умолчанию MergePageBreak)
+func SeparateBy(separator MergeSeparator) RenderOption {
+	return func(o *renderOptions) {
+		o.separator = separator
+	}
+}
+
+// EmptyArrays - вывод строк таблицы для пустого массива (по умолчанию EmptyArrayRemove)
+// Строка с флагом [no-data] сразу после строк шаблона удаляется, если массив не пуст
+func EmptyArrays(mode EmptyArrayMode) RenderOption {
+	return func(o *renderOptions) {
+		o.emptyArrays = mode
+	}
+}
+
+// Locale - язык помощников форматирования (formatNumber, currency, date, ...): en (по умолчанию), ru, vi
+// Допускается имя с регионом (ru-RU), неизвестный язык заменяется на en
+func Locale(name string) RenderOption {
+	return func(o *renderOptions) {
+		o.locale = name
+	}
+}
+
+// skipped - пропускается ли часть документа
+func (o *renderOptions) skipped(name, kind string) bool {
+	return o.skip[name] || o.skip[kind]
+}
+
+// dataFor - данные для рендера части документа
+func (o *renderOptions) dataFor(name, kind string, v interface{}) interface{} {
+	if data, ok := o.data[name]; ok {
+		return data
+	}
+	if data, ok := o.data[kind]; ok {
+		return data
+	}
+	return v
+}
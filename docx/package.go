@@ -0,0 +1,176 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// xmlHeader - заголовок XML частей документа
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`
+
+// packageEntry - часть пакета DOCX
+type packageEntry struct {
+	header zip.FileHeader
+	// open - содержимое части, которая копируется без изменений
+	open func() (io.ReadCloser, error)
+	// raw - сжатое содержимое части, копируется без повторного сжатия
+	raw func() (io.Reader, error)
+}
+
+// packageEntries - части zip архива по порядку
+func packageEntries(z *zip.Reader) []packageEntry {
+	entries := make([]packageEntry, 0, len(z.File))
+	for _, zf := range z.File {
+		if zf != nil {
+			entries = append(entries, packageEntry{header: zf.FileHeader, open: zf.Open, raw: zf.OpenRaw})
+		}
+	}
+	return entries
+}
+
+// memoryEntry - часть пакета со сжатым содержимым в памяти
+func memoryEntry(header zip.FileHeader, raw []byte) packageEntry {
+	return packageEntry{header: header, raw: func() (io.Reader, error) {
+		return bytes.NewReader(raw), nil
+	}}
+}
+
+// partXML - содержимое разобранной части (document.xml, колонтитулы, сноски, комментарии)
+// ok == false для частей, которые копируются без изменений
+func (f *SimpleDocxFile) partXML(name string) (data []byte, ok bool, err error) {
+	if name == "word/document.xml" {
+		data, err = wordDocumentToXML(f.document)
+	} else if part := f.part(name); part != nil {
+		data, err = wordHeaderToXML(part)
+	} else {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	return append([]byte(xmlHeader), data...), true, nil
+}
+
+// writePackage - запись пакета DOCX
+// Разобранные части кодируются заново, остальные копируются из entries
+// в сжатом виде без распаковки. Порядок частей, метод сжатия и время изменения сохраняются
+func (f *SimpleDocxFile) writePackage(writer io.Writer, entries []packageEntry) error {
+	w := zip.NewWriter(writer)
+	for _, entry := range entries {
+		data, ok, err := f.partXML(entry.header.Name)
+		if err != nil {
+			return err
+		}
+		if !ok && entry.raw != nil {
+			if err := copyRawEntry(w, entry, f.deterministic); err != nil {
+				return err
+			}
+			continue
+		}
+		header := zip.FileHeader{Name: entry.header.Name, Comment: entry.header.Comment,
+			Method: entry.header.Method, Modified: entry.header.Modified}
+		if f.deterministic {
+			setFixedTime(&header)
+		}
+		wzf, err := w.CreateHeader(&header)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if _, err := wzf.Write(data); err != nil {
+				return err
+			}
+			continue
+		}
+		r, err := entry.open()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(wzf, r)
+		if closeErr := r.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// copyRawEntry - копирование сжатой части с исходными CRC и размерами
+func copyRawEntry(w *zip.Writer, entry packageEntry, deterministic bool) error {
+	header := entry.header
+	if deterministic {
+		setFixedTime(&header)
+	}
+	wzf, err := w.CreateRaw(&header)
+	if err != nil {
+		return err
+	}
+	r, err := entry.raw()
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(wzf, r)
+	return err
+}
+
+// setFixedTime - фиксированное время изменения части (1980-01-01 00:00, начало эпохи MS-DOS)
+// Поле расширенного времени (0x5455) удаляется из дополнительных данных
+func setFixedTime(header *zip.FileHeader) {
+	header.Modified = time.Time{}
+	header.ModifiedDate = 1<<5 | 1
+	header.ModifiedTime = 0
+	var extra []byte
+	for data := header.Extra; len(data) >= 4; {
+		tag := binary.LittleEndian.Uint16(data[0:2])
+		size := int(binary.LittleEndian.Uint16(data[2:4]))
+		if 4+size > len(data) {
+			extra = append(extra, data...)
+			break
+		}
+		if tag != 0x5455 {
+			extra = append(extra, data[:4+size]...)
+		}
+		data = data[4+size:]
+	}
+	header.Extra = extra
+}
+
+// saveFile - атомарная запись файла
+// Данные пишутся во временный файл рядом с fileName, который затем переименовывается,
+// поэтому при ошибке прежнее содержимое fileName не портится
+func saveFile(fileName string, write func(writer io.Writer) error) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(fileName); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, fileName)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+	}
+	return err
+}
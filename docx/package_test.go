@@ -0,0 +1,220 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMedia - несжимаемое содержимое рисунка размером size
+func testMedia(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
+
+func TestWriteCopiesMedia(t *testing.T) {
+	media := testMedia(1 << 20)
+	f, err := OpenBytes(testPackage(t, testParagraph("{{Name}}"), media))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := new(bytes.Buffer)
+	if err := f.Write(buffer); err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range z.File {
+		if file.Name != "word/media/image1.bin" {
+			continue
+		}
+		if file.Method != zip.Deflate {
+			t.Errorf("method %d, want %d", file.Method, zip.Deflate)
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, media) {
+			t.Error("media changed")
+		}
+		return
+	}
+	t.Error("media not found")
+}
+
+func TestDeterministicWrite(t *testing.T) {
+	body := testParagraph("{{Title}}") + testTable([]string{"{{Orders$Name}}", "{{Orders$Qty}}"})
+	// write - рендер и запись шаблона, части которого изменены в modified
+	write := func(modified time.Time, opts ...OpenOption) []byte {
+		f, err := OpenBytes(testZipAt(t, modified, testDocument(body), testMedia(1024)), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Render(testOrdersData); err != nil {
+			t.Fatal(err)
+		}
+		buffer := new(bytes.Buffer)
+		if err := f.Write(buffer); err != nil {
+			t.Fatal(err)
+		}
+		return buffer.Bytes()
+	}
+	first := write(time.Now(), Deterministic())
+	// Расширенное время в zip хранится с точностью до секунды
+	time.Sleep(1100 * time.Millisecond)
+	if second := write(time.Now(), Deterministic()); !bytes.Equal(first, second) {
+		t.Error("deterministic results written at different times differ")
+	}
+	if second := write(time.Now().Add(time.Hour), Deterministic()); !bytes.Equal(first, second) {
+		t.Error("deterministic results of parts with different modification times differ")
+	}
+	// Без параметра время изменения частей сохраняется
+	if bytes.Equal(write(time.Now()), write(time.Now().Add(time.Hour))) {
+		t.Error("results of parts with different modification times are equal without Deterministic")
+	}
+}
+
+// readAllEntries - части пакета, которые читаются целиком и сжимаются заново (запись без копирования сжатых данных)
+func readAllEntries(z *zip.Reader) []packageEntry {
+	entries := packageEntries(z)
+	for index := range entries {
+		open := entries[index].open
+		entries[index].raw = nil
+		entries[index].open = func() (io.ReadCloser, error) {
+			r, err := open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		}
+	}
+	return entries
+}
+
+func BenchmarkWrite(b *testing.B) {
+	media := testMedia(8 << 20)
+	f, err := OpenBytes(testPackage(b, testParagraph("{{Name}}"), media))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarks := []struct {
+		name    string
+		entries func(z *zip.Reader) []packageEntry
+	}{
+		{"raw copy", packageEntries},
+		{"read all and deflate", readAllEntries},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(len(media)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := f.writePackage(ioutil.Discard, bm.entries(f.zipFile)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveFile(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "result.docx")
+	if err := os.WriteFile(fileName, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// В каталоге не должно оставаться временных файлов
+	checkDir := func(name string, want ...string) {
+		t.Helper()
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0)
+		for _, entry := range entries {
+			got = append(got, entry.Name())
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: got files %v, want %v", name, got, want)
+		}
+	}
+	checkContent := func(name string, want string) {
+		t.Helper()
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: got content %q, want %q", name, data, want)
+		}
+	}
+
+	// Ошибка записи: прежнее содержимое не портится
+	writeErr := errors.New("Write failed")
+	err := saveFile(fileName, func(writer io.Writer) error {
+		writer.Write([]byte("partial"))
+		return writeErr
+	})
+	if err != writeErr {
+		t.Errorf("write error: got %v, want %v", err, writeErr)
+	}
+	checkContent("write error", "old")
+	checkDir("write error", "result.docx")
+
+	// Ошибка переименования поверх непустого каталога
+	target := filepath.Join(dir, "folder")
+	if err := os.MkdirAll(filepath.Join(target, "item"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = saveFile(target, func(writer io.Writer) error {
+		_, err := writer.Write([]byte("new"))
+		return err
+	})
+	if err == nil {
+		t.Error("rename onto directory: want error")
+	}
+	checkDir("rename error", "folder", "result.docx")
+
+	// Успешная запись заменяет файл и сохраняет права доступа
+	err = saveFile(fileName, func(writer io.Writer) error {
+		_, err := writer.Write([]byte("new"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContent("save", "new")
+	checkDir("save", "folder", "result.docx")
+	if info, err := os.Stat(fileName); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("save: got mode %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	// Нет каталога для временного файла
+	if err := saveFile(filepath.Join(dir, "missing", "result.docx"), func(io.Writer) error { return nil }); err == nil {
+		t.Error("missing directory: want error")
+	}
+}
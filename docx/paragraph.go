@@ -13,6 +13,8 @@ type ParagraphItem struct {
 	RsidRDefault string `xml:"rsidRDefault,attr,omitempty"`
 	RsidP        string `xml:"rsidP,attr,omitempty"`
 	RsidRPr      string `xml:"rsidRPr,attr,omitempty"`
+	// Attrs - атрибуты параграфа в исходном порядке (w14:paraId, w14:textId ...)
+	Attrs []xml.Attr `xml:"-"`
 }
 
 // ParagraphParams - параметры параграфа
@@ -22,9 +24,11 @@ type ParagraphParams struct {
 	Jc            *StringValue  `xml:"jc,omitempty"`
 	Bidi          *IntValue     `xml:"bidi,omitempty"`
 	PBdr          *PBdrValue    `xml:"pBdr,omitempty"`
-	WindowControl *StringValue  `xml:"windowControl,omitempty"`
+	WindowControl *StringValue  `xml:"widowControl,omitempty"`
 	Ind           *MarginValue  `xml:"ind,omitempty"`
 	Rpr           *RecordParams `xml:"rPr,omitempty"`
+	SectPr        *BodyParams   `xml:"sectPr,omitempty"`
+	Extra         Properties    `xml:"-"`
 }
 
 type WParagraphParams struct {
@@ -33,9 +37,22 @@ type WParagraphParams struct {
 	Jc            *WStringValue  `xml:"w:jc,omitempty"`
 	Bidi          *WIntValue     `xml:"w:bidi,omitempty"`
 	PBdr          *WPBdrValue    `xml:"w:pBdr,omitempty"`
-	WindowControl *WStringValue  `xml:"w:windowControl,omitempty"`
+	WindowControl *WStringValue  `xml:"w:widowControl,omitempty"`
 	Ind           *WMarginValue  `xml:"w:ind,omitempty"`
 	Rpr           *WRecordParams `xml:"w:rPr,omitempty"`
+	SectPr        *WBodyParams   `xml:"w:sectPr,omitempty"`
+	Extra         Properties     `xml:"-"`
+}
+
+// UnmarshalXML - декодирование с сохранением неизвестных свойств
+func (pp *ParagraphParams) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	type plain ParagraphParams
+	return decodeProperties(decoder, start, (*plain)(pp), &pp.Extra)
+}
+
+// marshalXML - кодирование вместе с неизвестными свойствами
+func (wp WParagraphParams) marshalXML(encoder *xmlEncoder, start xml.StartElement) error {
+	return encodeProperties(encoder, start, &wp, &wp.Extra, paragraphParamsOrder)
 }
 
 func (pp *ParagraphParams) ToWParagraphParams() *WParagraphParams {
@@ -64,6 +81,11 @@ func (pp *ParagraphParams) ToWParagraphParams() *WParagraphParams {
 	if pp.Rpr != nil {
 		wp.Rpr = pp.Rpr.ToWRecordParams()
 	}
+	if pp.SectPr != nil {
+		sectPr := pp.SectPr.ToWBodyParams()
+		wp.SectPr = &sectPr
+	}
+	wp.Extra = pp.Extra.unchanged(pp)
 	return &wp
 }
 
@@ -133,51 +155,18 @@ func (item *ParagraphItem) Clone() DocItem {
 	if item.Params.Rpr != nil {
 		result.Params.Rpr = item.Params.Rpr.Clone()
 	}
-	return result
-}
-
-func (item *ParagraphParams) decode(decoder *xml.Decoder) error {
-	if decoder != nil {
-		var end bool
-		for !end {
-			token, _ := decoder.Token()
-			if token == nil {
-				break
-			}
-			switch element := token.(type) {
-			case xml.StartElement:
-				{
-					if element.Name.Local == "pBdr" {
-						decoder.DecodeElement(&item.PBdr, &element)
-					} else if element.Name.Local == "pStyle" {
-						decoder.DecodeElement(&item.Style, &element)
-					} else if element.Name.Local == "spacing" {
-						decoder.DecodeElement(&item.Spacing, &element)
-					} else if element.Name.Local == "jc" {
-						decoder.DecodeElement(&item.Jc, &element)
-					} else if element.Name.Local == "bidi" {
-						decoder.DecodeElement(&item.Bidi, &element)
-					} else if element.Name.Local == "widowControl" {
-						decoder.DecodeElement(&item.WindowControl, &element)
-					} else if element.Name.Local == "ind" {
-						decoder.DecodeElement(&item.Ind, &element)
-					} else if element.Name.Local == "rPr" {
-						decoder.DecodeElement(&item.Rpr, &element)
-					}
-
-				}
-			case xml.EndElement:
-				{
-					if element.Name.Local == "pPr" {
-						end = true
-					}
-				}
-			}
-		}
-		return nil
+	if item.Params.SectPr != nil {
+		result.Params.SectPr = item.Params.SectPr.Clone()
 	}
-	return errors.New("Not have decoder")
-
+	result.Params.Extra = item.Params.Extra.Clone()
+	result.RsidR = item.RsidR
+	result.RsidRDefault = item.RsidRDefault
+	result.RsidP = item.RsidP
+	result.RsidRPr = item.RsidRPr
+	if len(item.Attrs) > 0 {
+		result.Attrs = append([]xml.Attr(nil), item.Attrs...)
+	}
+	return result
 }
 
 // Декодирование параграфа
@@ -193,7 +182,7 @@ func (item *ParagraphItem) decode(decoder *xml.Decoder) error {
 			case xml.StartElement:
 				{
 					if element.Name.Local == "pPr" {
-						item.Params.decode(decoder)
+						decoder.DecodeElement(&item.Params, &element)
 					} else {
 						i := decodeItem(&element, decoder)
 						if i != nil {
@@ -217,26 +206,21 @@ func (item *ParagraphItem) decode(decoder *xml.Decoder) error {
 /* КОДИРОВАНИЕ */
 
 // Кодирование параграфа
-func (item *ParagraphItem) encode(encoder *xml.Encoder) error {
+func (item *ParagraphItem) encode(encoder *xmlEncoder) error {
 	if encoder != nil {
-		//
-		// RsidR        string `xml:"rsidR,attr,omitempty"`
-		// RsidRDefault string `xml:"rsidRDefault,attr,omitempty"`
-		// RsidP        string `xml:"rsidP,attr,omitempty"`
-		rsidR := xml.Attr{Name: xml.Name{Local: "w:" + "rsidR"}, Value: item.RsidR}
-		rsidRDefault := xml.Attr{Name: xml.Name{Local: "w:" + "rsidRDefault"}, Value: item.RsidRDefault}
-		rsidP := xml.Attr{Name: xml.Name{Local: "w:" + "rsidP"}, Value: item.RsidP}
-		rsidRPr := xml.Attr{Name: xml.Name{Local: "w:" + "rsidRPr"}, Value: item.RsidRPr}
+		rsidR := xml.Attr{Name: xml.Name{Space: nsW, Local: "rsidR"}, Value: item.RsidR}
+		rsidRDefault := xml.Attr{Name: xml.Name{Space: nsW, Local: "rsidRDefault"}, Value: item.RsidRDefault}
+		rsidP := xml.Attr{Name: xml.Name{Space: nsW, Local: "rsidP"}, Value: item.RsidP}
+		rsidRPr := xml.Attr{Name: xml.Name{Space: nsW, Local: "rsidRPr"}, Value: item.RsidRPr}
 		// Начало параграфа
-
-		start := xml.StartElement{Name: xml.Name{Local: "w:" + item.Tag()},
-			Attr: []xml.Attr{rsidR, rsidRDefault, rsidP, rsidRPr}}
+		start := xml.StartElement{Name: xml.Name{Space: nsW, Local: item.Tag()},
+			Attr: itemAttrs(item.Attrs, rsidR, rsidRDefault, rsidP, rsidRPr)}
 		if err := encoder.EncodeToken(start); err != nil {
 			return err
 		}
 		// Параметры параграфа
 
-		if err := encoder.EncodeElement(item.Params.ToWParagraphParams(), xml.StartElement{Name: xml.Name{Local: "w:" + "pPr"}}); err != nil {
+		if err := encoder.EncodeElement(item.Params.ToWParagraphParams(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "pPr"}}); err != nil {
 			return err
 		}
 		// Кодируем составные элементы
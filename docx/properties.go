@@ -0,0 +1,292 @@
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Properties - неизвестные элементы и атрибуты блока свойств (pPr, rPr, tcPr, tblPr, sectPr, trPr, tblPrEx)
+// Сохраняются при декодировании и записываются обратно в порядке схемы
+type Properties struct {
+	Attrs []xml.Attr
+	Items []*RawItem
+	// source - блок свойств был в исходном документе (пустой блок тоже записывается)
+	source bool
+	// sources - исходные известные элементы по имени
+	// Записываются как есть, пока значение поля структуры не изменилось
+	sources map[string][]*RawItem
+}
+
+// Clone (Properties) - клонирование
+func (p *Properties) Clone() Properties {
+	var result Properties
+	if len(p.Attrs) > 0 {
+		result.Attrs = append([]xml.Attr(nil), p.Attrs...)
+	}
+	for _, item := range p.Items {
+		result.Items = append(result.Items, item.Clone().(*RawItem))
+	}
+	result.source = p.source
+	if len(p.sources) > 0 {
+		result.sources = make(map[string][]*RawItem, len(p.sources))
+		for name, items := range p.sources {
+			result.sources[name] = items
+		}
+	}
+	return result
+}
+
+// unchanged (Properties) - копия для кодирования структуры v
+// В sources остаются исходные элементы полей, значение которых не изменилось после декодирования
+func (p *Properties) unchanged(v interface{}) Properties {
+	result := *p
+	if len(p.sources) == 0 {
+		return result
+	}
+	result.sources = make(map[string][]*RawItem, len(p.sources))
+	val := reflect.ValueOf(v).Elem()
+	for _, field := range xmlFieldsOf(val.Type()) {
+		items, ok := p.sources[field.name.Local]
+		if !ok || field.attr {
+			continue
+		}
+		value := val.Field(field.index)
+		source := reflect.New(value.Type())
+		for _, item := range items {
+			if err := xml.NewTokenDecoder(&tokenSlice{tokens: item.Tokens}).Decode(source.Interface()); err != nil {
+				return result
+			}
+		}
+		if reflect.DeepEqual(source.Elem().Interface(), value.Interface()) {
+			result.sources[field.name.Local] = items
+		}
+	}
+	return result
+}
+
+// Порядок дочерних элементов блоков свойств по схеме ECMA-376
+var (
+	paragraphParamsOrder = schemaOrder("pStyle", "keepNext", "keepLines", "pageBreakBefore", "framePr",
+		"widowControl", "numPr", "suppressLineNumbers", "pBdr", "shd", "tabs", "suppressAutoHyphens",
+		"kinsoku", "wordWrap", "overflowPunct", "topLinePunct", "autoSpaceDE", "autoSpaceDN", "bidi",
+		"adjustRightInd", "snapToGrid", "spacing", "ind", "contextualSpacing", "mirrorIndents",
+		"suppressOverlap", "jc", "textDirection", "textAlignment", "textboxTightWrap", "outlineLvl",
+		"divId", "cnfStyle", "rPr", "sectPr", "pPrChange")
+	recordParamsOrder = schemaOrder("ins", "del", "moveFrom", "moveTo", "rStyle", "rFonts", "b", "bCs",
+		"i", "iCs", "caps", "smallCaps", "strike", "dstrike", "outline", "shadow", "emboss", "imprint",
+		"noProof", "snapToGrid", "vanish", "webHidden", "color", "spacing", "w", "kern", "position",
+		"sz", "szCs", "highlight", "u", "effect", "bdr", "shd", "fitText", "vertAlign", "rtl", "cs",
+		"em", "lang", "eastAsianLayout", "specVanish", "oMath", "rPrChange")
+	tableCellParamsOrder = schemaOrder("cnfStyle", "tcW", "gridSpan", "hMerge", "vMerge", "tcBorders",
+		"shd", "noWrap", "tcMar", "textDirection", "tcFitText", "vAlign", "hideMark", "headers",
+		"cellIns", "cellDel", "cellMerge", "tcPrChange")
+	tableParamsOrder = schemaOrder("tblStyle", "tblpPr", "tblOverlap", "bidiVisual",
+		"tblStyleRowBandSize", "tblStyleColBandSize", "tblW", "jc", "tblCellSpacing", "tblInd",
+		"tblBorders", "shd", "tblLayout", "tblCellMar", "tblLook", "tblCaption", "tblDescription",
+		"tblPrChange")
+	tableRowParamsOrder = schemaOrder("cnfStyle", "divId", "gridBefore", "gridAfter", "wBefore", "wAfter",
+		"cantSplit", "trHeight", "tblHeader", "tblCellSpacing", "jc", "hidden", "ins", "del", "trPrChange")
+	tableParamsExOrder = schemaOrder("tblW", "jc", "tblCellSpacing", "tblInd", "tblBorders", "shd",
+		"tblLayout", "tblCellMar", "tblLook", "tblPrExChange")
+	bodyParamsOrder = schemaOrder("headerReference", "footerReference", "footnotePr", "endnotePr",
+		"type", "pgSz", "pgMar", "paperSrc", "pgBorders", "lnNumType", "pgNumType", "cols", "formProt",
+		"vAlign", "noEndnote", "titlePg", "textDirection", "bidi", "rtlGutter", "docGrid",
+		"printerSettings", "sectPrChange")
+)
+
+// schemaOrder - индексы элементов по порядку
+func schemaOrder(names ...string) map[string]int {
+	result := make(map[string]int, len(names))
+	for index, name := range names {
+		result[name] = index
+	}
+	return result
+}
+
+/* ДЕКОДИРОВАНИЕ */
+
+// knownNames - имена элементов и атрибутов, которые декодирует структура
+type knownNames struct {
+	elements map[string]bool
+	attrs    map[string]bool
+}
+
+var knownNamesCache sync.Map
+
+// knownNamesOf - известные имена по тегам xml полей структуры
+func knownNamesOf(t reflect.Type) *knownNames {
+	if cached, ok := knownNamesCache.Load(t); ok {
+		return cached.(*knownNames)
+	}
+	result := &knownNames{elements: make(map[string]bool), attrs: make(map[string]bool)}
+	for index := 0; index < t.NumField(); index++ {
+		tag := t.Field(index).Tag.Get("xml")
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			name = name[i+1:]
+		}
+		isAttr := false
+		for _, flag := range parts[1:] {
+			if flag == "attr" {
+				isAttr = true
+			}
+		}
+		if isAttr {
+			result.attrs[name] = true
+		} else if len(name) > 0 {
+			result.elements[name] = true
+		}
+	}
+	knownNamesCache.Store(t, result)
+	return result
+}
+
+// tokenSlice - чтение ранее прочитанных токенов
+type tokenSlice struct {
+	tokens []xml.Token
+}
+
+func (s *tokenSlice) Token() (xml.Token, error) {
+	if len(s.tokens) == 0 {
+		return nil, io.EOF
+	}
+	token := s.tokens[0]
+	s.tokens = s.tokens[1:]
+	return token, nil
+}
+
+// decodeProperties - декодирование блока свойств в структуру v
+// Элементы и атрибуты, которых нет в структуре, сохраняются в extra
+func decodeProperties(decoder *xml.Decoder, start xml.StartElement, v interface{}, extra *Properties) error {
+	raw := newRawItem(&start)
+	if err := raw.decode(decoder); err != nil {
+		return err
+	}
+	known := knownNamesOf(reflect.TypeOf(v).Elem())
+	*extra = Properties{source: true}
+	root := xml.StartElement{Name: start.Name}
+	for _, attr := range start.Attr {
+		if known.attrs[attr.Name.Local] || attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			root.Attr = append(root.Attr, attr)
+		} else {
+			extra.Attrs = append(extra.Attrs, attr)
+		}
+	}
+	tokens := []xml.Token{root}
+	// current - неизвестный элемент, source - исходный известный элемент
+	var current, source *RawItem
+	depth := 0
+	for _, token := range raw.Tokens[1:] {
+		switch element := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 && !known.elements[element.Name.Local] {
+				current = &RawItem{}
+				extra.Items = append(extra.Items, current)
+			} else if depth == 1 {
+				source = &RawItem{}
+				if extra.sources == nil {
+					extra.sources = make(map[string][]*RawItem)
+				}
+				extra.sources[element.Name.Local] = append(extra.sources[element.Name.Local], source)
+			}
+		case xml.EndElement:
+			depth--
+		}
+		if current != nil {
+			current.Tokens = append(current.Tokens, token)
+			if depth == 0 {
+				current = nil
+			}
+			continue
+		}
+		if source != nil {
+			source.Tokens = append(source.Tokens, token)
+			if depth == 0 {
+				source = nil
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	return xml.NewTokenDecoder(&tokenSlice{tokens: tokens}).Decode(v)
+}
+
+/* КОДИРОВАНИЕ */
+
+// propertyItem - дочерний элемент блока свойств при кодировании
+// Известное поле структуры (value) или неизвестный элемент (item)
+type propertyItem struct {
+	name  xml.Name
+	value reflect.Value
+	item  *RawItem
+}
+
+// encodeProperties - кодирование блока свойств из структуры v вместе с extra
+// Дочерние элементы записываются в порядке схемы order, неизмененные известные элементы - как в исходном документе
+// Незаданные поля не записываются, пустой блок записывается, только если он был в исходном документе
+func encodeProperties(encoder *xmlEncoder, start xml.StartElement, v interface{}, extra *Properties, order map[string]int) error {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	// Известные поля и неизвестные элементы сортируются по схеме
+	items := make([]propertyItem, 0)
+	for _, field := range xmlFieldsOf(val.Type()) {
+		value := val.Field(field.index)
+		if field.attr || field.chardata {
+			continue
+		}
+		if sources, ok := extra.sources[field.name.Local]; ok {
+			for _, item := range sources {
+				items = append(items, propertyItem{name: field.name, item: item})
+			}
+			continue
+		}
+		if isEmptyValue(value) || (value.Kind() == reflect.Struct && value.IsZero()) {
+			continue
+		}
+		items = append(items, propertyItem{name: field.name, value: value})
+	}
+	for _, item := range extra.Items {
+		items = append(items, propertyItem{name: xml.Name{Local: item.Tag()}, item: item})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return orderIndex(order, items[i].name.Local) < orderIndex(order, items[j].name.Local)
+	})
+	start.Attr = append(append(start.Attr, structAttrs(val)...), extra.Attrs...)
+	if len(items) == 0 && len(start.Attr) == 0 && !extra.source {
+		return nil
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.item != nil {
+			if err := item.item.encode(encoder); err != nil {
+				return err
+			}
+		} else if err := encoder.encodeValue(item.value, xml.StartElement{Name: item.name}); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// orderIndex - позиция элемента по схеме, неизвестные в конце
+func orderIndex(order map[string]int, name string) int {
+	if index, ok := order[name]; ok {
+		return index
+	}
+	return len(order)
+}
@@ -0,0 +1,73 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPropertiesOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"paragraph",
+			`<w:pPr><w:outlineLvl w:val="1"/><w:jc w:val="left"/><w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr><w:pStyle w:val="H1"/></w:pPr>`,
+			`<w:pPr><w:pStyle w:val="H1"/><w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr><w:jc w:val="left"/><w:outlineLvl w:val="1"/></w:pPr>`},
+		{"run",
+			`<w:rPr><w:caps/><w:spacing w:val="20"/><w:b/></w:rPr>`,
+			`<w:rPr><w:b/><w:caps/><w:spacing w:val="20"/></w:rPr>`},
+		{"table",
+			`<w:tblPr><w:tblCellMar><w:left w:w="10" w:type="dxa"/></w:tblCellMar><w:tblW w:w="9000" w:type="dxa"/></w:tblPr>`,
+			`<w:tblPr><w:tblW w:w="9000" w:type="dxa"/><w:tblCellMar><w:left w:w="10" w:type="dxa"/></w:tblCellMar></w:tblPr>`},
+		{"table exceptions",
+			`<w:tblPrEx><w:shd w:val="clear" w:fill="EEEEEE"/><w:tblW w:w="5000" w:type="dxa"/></w:tblPrEx>`,
+			`<w:tblPrEx><w:tblW w:w="5000" w:type="dxa"/><w:shd w:val="clear" w:fill="EEEEEE"/></w:tblPrEx>`},
+		{"row",
+			`<w:trPr><w:jc w:val="center"/><w:hidden/><w:trHeight w:val="400" w:hRule="exact"/><w:cantSplit/></w:trPr>`,
+			`<w:trPr><w:cantSplit/><w:trHeight w:val="400" w:hRule="exact"/><w:jc w:val="center"/><w:hidden/></w:trPr>`},
+		{"cell",
+			`<w:tcPr><w:textDirection w:val="btLr"/><w:tcW w:w="3000" w:type="dxa"/></w:tcPr>`,
+			`<w:tcPr><w:tcW w:w="3000" w:type="dxa"/><w:textDirection w:val="btLr"/></w:tcPr>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pPr, rPr, tblPr, tblPrEx, trPr, tcPr := "", "", `<w:tblPr/>`, "", "", ""
+			switch {
+			case strings.HasPrefix(test.source, "<w:pPr>"):
+				pPr = test.source
+			case strings.HasPrefix(test.source, "<w:rPr>"):
+				rPr = test.source
+			case strings.HasPrefix(test.source, "<w:tblPr>"):
+				tblPr = test.source
+			case strings.HasPrefix(test.source, "<w:tblPrEx>"):
+				tblPrEx = test.source
+			case strings.HasPrefix(test.source, "<w:trPr>"):
+				trPr = test.source
+			default:
+				tcPr = test.source
+			}
+			f := testOpen(t, `<w:tbl>`+tblPr+`<w:tblGrid><w:gridCol w:w="3000"/></w:tblGrid><w:tr>`+tblPrEx+trPr+
+				`<w:tc>`+tcPr+`<w:p>`+pPr+`<w:r>`+rPr+`<w:t>a</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`)
+			if result := testDocumentXML(t, f); !strings.Contains(result, test.want) {
+				t.Errorf("want %s in:\n%s", test.want, result)
+			}
+		})
+	}
+}
+
+func TestPropertiesExpandedRows(t *testing.T) {
+	f := testOpen(t, `<w:tbl><w:tblPr/><w:tblGrid><w:gridCol w:w="3000"/></w:tblGrid><w:tr w:rsidTrPr="00C3">`+
+		`<w:tblPrEx><w:tblW w:w="5000" w:type="dxa"/></w:tblPrEx><w:trPr><w:cantSplit/><w:trHeight w:val="400" w:hRule="exact"/></w:trPr>`+
+		`<w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/><w:textDirection w:val="btLr"/></w:tcPr>`+testParagraph("{{Orders$Name}}")+`</w:tc></w:tr></w:tbl>`)
+	if err := f.Render(testOrdersData); err != nil {
+		t.Fatal(err)
+	}
+	result := testDocumentXML(t, f)
+	want := `<w:tr w:rsidTrPr="00C3"><w:tblPrEx><w:tblW w:w="5000" w:type="dxa"/></w:tblPrEx>` +
+		`<w:trPr><w:cantSplit/><w:trHeight w:val="400" w:hRule="exact"/></w:trPr>` +
+		`<w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/><w:textDirection w:val="btLr"/></w:tcPr>`
+	if count := strings.Count(result, want); count != len(testOrdersData.Orders) {
+		t.Errorf("want %d rows %s in:\n%s", len(testOrdersData.Orders), want, result)
+	}
+}
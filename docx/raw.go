@@ -0,0 +1,85 @@
+package docx
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+// RawItem - неизвестный элемент документа
+// Хранится поддеревом XML токенов и записывается обратно без изменений
+type RawItem struct {
+	Tokens []xml.Token
+}
+
+// newRawItem - неизвестный элемент по открывающему тегу
+func newRawItem(element *xml.StartElement) *RawItem {
+	return &RawItem{Tokens: []xml.Token{element.Copy()}}
+}
+
+// Tag - имя тега элемента
+func (item *RawItem) Tag() string {
+	if len(item.Tokens) > 0 {
+		if start, ok := item.Tokens[0].(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+	return ""
+}
+
+// Type - тип элемента
+func (item *RawItem) Type() DocItemType {
+	return Raw
+}
+
+// PlainText - текст (содержимое неизвестных элементов не рендерится)
+func (item *RawItem) PlainText() string {
+	return ""
+}
+
+// Clone - клонирование
+func (item *RawItem) Clone() DocItem {
+	result := new(RawItem)
+	// Токены не изменяются, достаточно копии среза
+	result.Tokens = append([]xml.Token(nil), item.Tokens...)
+	return result
+}
+
+// Декодирование поддерева до закрывающего тега
+func (item *RawItem) decode(decoder *xml.Decoder) error {
+	if decoder != nil {
+		depth := 1
+		for depth > 0 {
+			token, err := decoder.Token()
+			if token == nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			switch token.(type) {
+			case xml.StartElement:
+				depth++
+			case xml.EndElement:
+				depth--
+			}
+			item.Tokens = append(item.Tokens, xml.CopyToken(token))
+		}
+		return nil
+	}
+	return errors.New("Not have decoder")
+}
+
+/* КОДИРОВАНИЕ */
+
+// Кодирование поддерева токенов
+func (item *RawItem) encode(encoder *xmlEncoder) error {
+	if encoder != nil {
+		for _, token := range item.Tokens {
+			if err := encoder.EncodeToken(token); err != nil {
+				return err
+			}
+		}
+		return encoder.Flush()
+	}
+	return errors.New("Not have encoder")
+}
@@ -21,13 +21,26 @@ func (t Text) ToWText() *WText {
 
 // RecordItem - record item
 type RecordItem struct {
-	Params  *RecordParams `xml:"rPr,omitempty"`
-	Text    Text          `xml:"t,omitempty"`
-	Tab     bool          `xml:"tab,omitempty"`
-	Break   bool          `xml:"br,omitempty"`
-	Drawing *Drawing      `xml:"drawing,omitempty"`
+	Params    *RecordParams `xml:"rPr,omitempty"`
+	Text      Text          `xml:"t,omitempty"`
+	Tab       bool          `xml:"tab,omitempty"`
+	Break     bool          `xml:"br,omitempty"`
+	BreakType string        `xml:"-"`
+	Drawing   *Drawing      `xml:"drawing,omitempty"`
+	// children - дочерние элементы записи в исходном порядке
+	children []recordChild
 }
 
+// recordChild - дочерний элемент записи: известный тег (t, tab, br, drawing),
+// значение которого хранится в полях записи, или неизвестный элемент (fldChar, instrText, sym ...)
+type recordChild struct {
+	tag  string
+	item DocItem
+}
+
+// recordFields - известные дочерние элементы записи в порядке вывода элементов, которых нет в исходной записи
+var recordFields = []string{"t", "drawing", "br", "tab"}
+
 // RecordParams - params record
 type RecordParams struct {
 	Fonts     *RecordFonts `xml:"rFonts,omitempty"`
@@ -44,6 +57,13 @@ type RecordParams struct {
 	VertAlign *StyleValue  `xml:"vertAlign,omitempty"`
 	Strike    *EmptyValue  `xml:"strike,omitempty"`
 	NoProof   *EmptyValue  `xml:"noProof,omitempty"`
+	Extra     Properties   `xml:"-"`
+}
+
+// UnmarshalXML - декодирование с сохранением неизвестных свойств
+func (rp *RecordParams) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	type plain RecordParams
+	return decodeProperties(decoder, start, (*plain)(rp), &rp.Extra)
 }
 
 func (rp *RecordParams) Clone() *RecordParams {
@@ -60,7 +80,7 @@ func (rp *RecordParams) Clone() *RecordParams {
 	}
 	if rp.Underline != nil {
 		result.Underline = new(ShadowValue)
-		result.Underline.Value = rp.Underline.Value
+		result.Underline.From(rp.Underline)
 	}
 	if rp.Color != nil {
 		result.Color = new(StringValue)
@@ -90,6 +110,21 @@ func (rp *RecordParams) Clone() *RecordParams {
 		result.Fonts.HandleANSI = rp.Fonts.HandleANSI
 		result.Fonts.HandleInt = rp.Fonts.HandleInt
 	}
+	if rp.Highlight != nil {
+		result.Highlight = new(StyleValue)
+		result.Highlight.From(rp.Highlight)
+	}
+	if rp.VertAlign != nil {
+		result.VertAlign = new(StyleValue)
+		result.VertAlign.From(rp.VertAlign)
+	}
+	if rp.Strike != nil {
+		result.Strike = new(EmptyValue)
+	}
+	if rp.NoProof != nil {
+		result.NoProof = new(EmptyValue)
+	}
+	result.Extra = rp.Extra.Clone()
 	return result
 }
 
@@ -147,6 +182,7 @@ func (rp *RecordParams) ToWRecordParams() *WRecordParams {
 	if rp.NoProof != nil {
 		wrp.NoProof = (*WEmptyValue)(rp.NoProof)
 	}
+	wrp.Extra = rp.Extra.unchanged(rp)
 	return &wrp
 }
 
@@ -165,6 +201,12 @@ type WRecordParams struct {
 	VertAlign *WStyleValue  `xml:"w:vertAlign,omitempty"`
 	Strike    *WEmptyValue  `xml:"w:strike,omitempty"`
 	NoProof   *WEmptyValue  `xml:"w:noProof,omitempty"`
+	Extra     Properties    `xml:"-"`
+}
+
+// marshalXML - кодирование вместе с неизвестными свойствами
+func (wrp WRecordParams) marshalXML(encoder *xmlEncoder, start xml.StartElement) error {
+	return encodeProperties(encoder, start, &wrp, &wrp.Extra, recordParamsOrder)
 }
 
 // RecordFonts - fonts in record
@@ -205,6 +247,15 @@ func (item *RecordItem) Clone() DocItem {
 	result.Text = item.Text
 	result.Tab = item.Tab
 	result.Break = item.Break
+	result.BreakType = item.BreakType
+	// Рисунок при рендере не изменяется
+	result.Drawing = item.Drawing
+	for _, child := range item.children {
+		if child.item != nil {
+			child.item = child.item.Clone()
+		}
+		result.children = append(result.children, child)
+	}
 	// Клонируем параметры
 
 	if item.Params == nil {
@@ -228,14 +279,32 @@ func (item *RecordItem) decode(decoder *xml.Decoder) error {
 				{
 					if element.Name.Local == "rPr" {
 						decoder.DecodeElement(&item.Params, &element)
-					} else if element.Name.Local == "t" {
-						decoder.DecodeElement(&item.Text, &element)
-					} else if element.Name.Local == "br" {
-						item.Break = true
-					} else if element.Name.Local == "tab" {
-						item.Tab = true
-					} else if element.Name.Local == "drawing" {
-						decoder.DecodeElement(&item.Drawing, &element)
+					} else if item.hasChild(element.Name.Local) || !isRecordField(element.Name.Local) {
+						// Повторный известный элемент сохраняется как неизвестный, чтобы не потерять его значение
+						extra := newRawItem(&element)
+						if err := extra.decode(decoder); err != nil {
+							return err
+						}
+						item.children = append(item.children, recordChild{item: extra})
+					} else {
+						switch element.Name.Local {
+						case "t":
+							decoder.DecodeElement(&item.Text, &element)
+						case "br":
+							item.Break = true
+							for _, attr := range element.Attr {
+								if attr.Name.Local == "type" {
+									item.BreakType = attr.Value
+								}
+							}
+							decoder.Skip()
+						case "tab":
+							item.Tab = true
+							decoder.Skip()
+						case "drawing":
+							decoder.DecodeElement(&item.Drawing, &element)
+						}
+						item.children = append(item.children, recordChild{tag: element.Name.Local})
 					}
 				}
 			case xml.EndElement:
@@ -254,60 +323,97 @@ func (item *RecordItem) decode(decoder *xml.Decoder) error {
 /* КОДИРОВАНИЕ */
 
 // Кодирование записи
-func (item *RecordItem) encode(encoder *xml.Encoder) error {
+func (item *RecordItem) encode(encoder *xmlEncoder) error {
 	if encoder != nil {
 		// Начало записи
-		start := xml.StartElement{Name: xml.Name{Local: "w:" + item.Tag()}}
+		start := xml.StartElement{Name: xml.Name{Space: nsW, Local: item.Tag()}}
 		if err := encoder.EncodeToken(start); err != nil {
 			return err
 		}
 		// Параметры записи
 		if item.Params != nil {
-			if err := encoder.EncodeElement(item.Params.ToWRecordParams(), xml.StartElement{Name: xml.Name{Local: "w:" + "rPr"}}); err != nil {
+			if err := encoder.EncodeElement(item.Params.ToWRecordParams(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "rPr"}}); err != nil {
 				return err
 			}
 		}
-		// Текст
-		if err := encoder.EncodeElement(item.Text.ToWText(), xml.StartElement{Name: xml.Name{Local: "w:" + "t"}}); err != nil {
-			return err
+		// Известные элементы, которых нет в исходной записи
+		for _, tag := range recordFields {
+			if !item.hasChild(tag) {
+				if err := item.encodeField(encoder, tag, false); err != nil {
+					return err
+				}
+			}
 		}
-		// todo: Drawing
-		if item.Drawing != nil {
-			if err := encoder.EncodeElement(item.Drawing.ToWDrawing(), xml.StartElement{Name: xml.Name{Local: "w:" + "drawing"}}); err != nil {
+		// Дочерние элементы в исходном порядке
+		for _, child := range item.children {
+			if child.item != nil {
+				if err := child.item.encode(encoder); err != nil {
+					return err
+				}
+			} else if err := item.encodeField(encoder, child.tag, true); err != nil {
 				return err
 			}
 		}
-		// <br />
+		// Конец записи
+		if err := encoder.EncodeToken(start.End()); err != nil {
+			return err
+		}
+		return encoder.Flush()
+	}
+	return errors.New("Not have encoder")
+}
+
+// encodeField - кодирование известного дочернего элемента записи
+// Пустой текст выводится, только если он был в исходной записи
+func (item *RecordItem) encodeField(encoder *xmlEncoder, tag string, source bool) error {
+	switch tag {
+	case "t":
+		if len(item.Text.Value) > 0 || source {
+			return encoder.EncodeElement(item.Text.ToWText(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "t"}})
+		}
+	case "drawing":
+		if item.Drawing != nil {
+			return encoder.EncodeElement(item.Drawing.ToWDrawing(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "drawing"}})
+		}
+	case "br":
 		if item.Break {
-			startBr := xml.StartElement{Name: xml.Name{Local: "w:" + "br"}}
-			if err := encoder.EncodeToken(startBr); err != nil {
-				return err
-			}
-			if err := encoder.EncodeToken(startBr.End()); err != nil {
-				return err
+			startBr := xml.StartElement{Name: xml.Name{Space: nsW, Local: "br"}}
+			if len(item.BreakType) > 0 {
+				startBr.Attr = []xml.Attr{{Name: xml.Name{Space: nsW, Local: "type"}, Value: item.BreakType}}
 			}
-			if err := encoder.Flush(); err != nil {
+			if err := encoder.EncodeToken(startBr); err != nil {
 				return err
 			}
+			return encoder.EncodeToken(startBr.End())
 		}
-		// Tab
+	case "tab":
 		if item.Tab {
-			startTab := xml.StartElement{Name: xml.Name{Local: "w:" + "tab"}}
+			startTab := xml.StartElement{Name: xml.Name{Space: nsW, Local: "tab"}}
 			if err := encoder.EncodeToken(startTab); err != nil {
 				return err
 			}
-			if err := encoder.EncodeToken(startTab.End()); err != nil {
-				return err
-			}
-			if err := encoder.Flush(); err != nil {
-				return err
-			}
+			return encoder.EncodeToken(startTab.End())
 		}
-		// Конец записи
-		if err := encoder.EncodeToken(start.End()); err != nil {
-			return err
+	}
+	return nil
+}
+
+// hasChild - известный элемент tag есть в исходной записи
+func (item *RecordItem) hasChild(tag string) bool {
+	for _, child := range item.children {
+		if child.item == nil && child.tag == tag {
+			return true
 		}
-		return encoder.Flush()
 	}
-	return errors.New("Not have encoder")
+	return false
+}
+
+// isRecordField - элемент хранится в полях записи
+func isRecordField(tag string) bool {
+	for _, field := range recordFields {
+		if field == tag {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,45 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecordChildrenOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		run  string
+		want string
+	}{
+		{"sym before text", `<w:r><w:sym w:font="Wingdings" w:char="F0FC"/><w:t>ok</w:t></w:r>`, ""},
+		{"cr between texts", `<w:r><w:t>a</w:t><w:cr/><w:t>b</w:t></w:r>`, ""},
+		{"tab before text", `<w:r><w:tab/><w:t>a</w:t></w:r>`, ""},
+		{"field begin", `<w:r><w:fldChar w:fldCharType="begin"/></w:r>`, ""},
+		{"field instruction", `<w:r><w:instrText xml:space="preserve"> PAGE </w:instrText></w:r>`, ""},
+		{"break only", `<w:r><w:br w:type="page"/></w:r>`, ""},
+		{"empty text", `<w:r><w:t></w:t></w:r>`, `<w:r><w:t/></w:r>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, `<w:p>`+test.run+`</w:p>`)
+			want := test.want
+			if len(want) == 0 {
+				want = test.run
+			}
+			if result := testDocumentXML(t, f); !strings.Contains(result, want) {
+				t.Errorf("want %s in:\n%s", want, result)
+			}
+		})
+	}
+}
+
+func TestRecordCloneChildren(t *testing.T) {
+	f := testOpen(t, `<w:p><w:r><w:sym w:font="Wingdings" w:char="F0FC"/><w:t>{{Name}}</w:t></w:r></w:p>`)
+	if err := f.Render(map[string]interface{}{"Name": "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `<w:r><w:sym w:font="Wingdings" w:char="F0FC"/><w:t>ok</w:t></w:r>`
+	if result := testDocumentXML(t, f); !strings.Contains(result, want) {
+		t.Errorf("want %s in:\n%s", want, result)
+	}
+}
@@ -0,0 +1,44 @@
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+)
+
+// Relationship - связь части документа (word/_rels/document.xml.rels)
+type Relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr,omitempty"`
+}
+
+// Relationships - список связей документа
+type Relationships struct {
+	Items []Relationship `xml:"Relationship"`
+}
+
+// Decode (Relationships) - декодирование связей
+func (r *Relationships) Decode(reader io.Reader) error {
+	return xml.NewDecoder(reader).Decode(r)
+}
+
+// PartName - имя части пакета по идентификатору связи
+func (r *Relationships) PartName(id string) (string, bool) {
+	if r != nil {
+		for _, rel := range r.Items {
+			if rel.ID == id {
+				if rel.TargetMode == "External" {
+					return "", false
+				}
+				if strings.HasPrefix(rel.Target, "/") {
+					return strings.TrimPrefix(rel.Target, "/"), true
+				}
+				return path.Join("word", rel.Target), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,85 @@
+package docx
+
+// Оформление строк и ячеек таблицы (для директив и обработчиков OnRow/OnCell)
+
+// SetBold (TableCell) - жирный шрифт текста ячейки
+func (cell *TableCell) SetBold(bold bool) {
+	setBoldToCell(bold, cell)
+}
+
+// SetItalic (TableCell) - курсив текста ячейки
+func (cell *TableCell) SetItalic(italic bool) {
+	forEachRecordInCell(cell, func(record *RecordItem) {
+		if italic {
+			recordParams(record).Italic = new(EmptyValue)
+		} else if record.Params != nil {
+			record.Params.Italic = nil
+		}
+	})
+}
+
+// SetColor (TableCell) - цвет текста ячейки (RRGGBB или auto)
+func (cell *TableCell) SetColor(color string) {
+	forEachRecordInCell(cell, func(record *RecordItem) {
+		recordParams(record).Color = &StringValue{Value: color}
+	})
+}
+
+// SetShading (TableCell) - заливка ячейки (RRGGBB), пустая строка убирает заливку
+func (cell *TableCell) SetShading(fill string) {
+	if len(fill) == 0 {
+		cell.Params.Shadow = nil
+		return
+	}
+	cell.Params.Shadow = &ShadowValue{Value: "clear", Color: "auto", Fill: fill}
+}
+
+// SetAlign (TableCell) - выравнивание параграфов ячейки (left, center, right, both)
+func (cell *TableCell) SetAlign(align string) {
+	for _, item := range cell.Items {
+		if p, ok := item.(*ParagraphItem); ok {
+			p.Params.Jc = &StringValue{Value: align}
+		}
+	}
+}
+
+// SetVerticalAlign (TableCell) - вертикальное выравнивание ячейки (top, center, bottom)
+func (cell *TableCell) SetVerticalAlign(align string) {
+	cell.Params.VerticalAlign = &StringValue{Value: align}
+}
+
+// SetBold (TableRow) - жирный шрифт текста всех ячеек строки
+func (row *TableRow) SetBold(bold bool) {
+	for _, cell := range row.Cells {
+		cell.SetBold(bold)
+	}
+}
+
+// SetItalic (TableRow) - курсив текста всех ячеек строки
+func (row *TableRow) SetItalic(italic bool) {
+	for _, cell := range row.Cells {
+		cell.SetItalic(italic)
+	}
+}
+
+// SetColor (TableRow) - цвет текста всех ячеек строки
+func (row *TableRow) SetColor(color string) {
+	for _, cell := range row.Cells {
+		cell.SetColor(color)
+	}
+}
+
+// SetShading (TableRow) - заливка всех ячеек строки
+func (row *TableRow) SetShading(fill string) {
+	for _, cell := range row.Cells {
+		cell.SetShading(fill)
+	}
+}
+
+// recordParams - параметры записи, создаются при отсутствии
+func recordParams(record *RecordItem) *RecordParams {
+	if record.Params == nil {
+		record.Params = new(RecordParams)
+	}
+	return record.Params
+}
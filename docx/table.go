@@ -31,11 +31,44 @@ func (g *TableGrid) ToWGirdParams() *WTableGrid {
 
 // TableParamsEx - Other params table
 type TableParamsEx struct {
-	Shadow ShadowValue `xml:"shd"`
+	Shadow *ShadowValue `xml:"shd,omitempty"`
+	Extra  Properties   `xml:"-"`
+}
+
+// UnmarshalXML - декодирование с сохранением неизвестных свойств
+func (tpe *TableParamsEx) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	type plain TableParamsEx
+	return decodeProperties(decoder, start, (*plain)(tpe), &tpe.Extra)
+}
+
+// Clone (TableParamsEx) - клонирование
+func (tpe *TableParamsEx) Clone() *TableParamsEx {
+	result := new(TableParamsEx)
+	if tpe.Shadow != nil {
+		result.Shadow = new(ShadowValue)
+		result.Shadow.From(tpe.Shadow)
+	}
+	result.Extra = tpe.Extra.Clone()
+	return result
 }
 
 type WTableParamsEx struct {
-	Shadow ShadowValue `xml:"w:shd"`
+	Shadow *WShadowValue `xml:"w:shd,omitempty"`
+	Extra  Properties    `xml:"-"`
+}
+
+// marshalXML - кодирование вместе с неизвестными свойствами
+func (wtpe WTableParamsEx) marshalXML(encoder *xmlEncoder, start xml.StartElement) error {
+	return encodeProperties(encoder, start, &wtpe, &wtpe.Extra, tableParamsExOrder)
+}
+
+func (tpe *TableParamsEx) toWTableParamsEx() *WTableParamsEx {
+	wtpe := WTableParamsEx{}
+	if tpe.Shadow != nil {
+		wtpe.Shadow = (*WShadowValue)(tpe.Shadow)
+	}
+	wtpe.Extra = tpe.Extra.unchanged(tpe)
+	return &wtpe
 }
 
 // Tag - имя тега элемента
@@ -102,6 +135,7 @@ func (item *TableItem) Clone() DocItem {
 		result.Params.Look = new(LookValue)
 		result.Params.Look.From(item.Params.Look)
 	}
+	result.Params.Extra = item.Params.Extra.Clone()
 	// Клонирование строк
 	result.Rows = make([]*TableRow, 0)
 	for _, row := range item.Rows {
@@ -123,6 +157,13 @@ type TableParams struct {
 	Layout  *TableLayout  `xml:"tblLayout,omitempty"`
 	DocGrid *IntValue     `xml:"docGrid,omitempty"`
 	Look    *LookValue    `xml:"tblLook,omitempty"`
+	Extra   Properties    `xml:"-"`
+}
+
+// UnmarshalXML - декодирование с сохранением неизвестных свойств
+func (tp *TableParams) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	type plain TableParams
+	return decodeProperties(decoder, start, (*plain)(tp), &tp.Extra)
 }
 
 type WTableParams struct {
@@ -135,6 +176,12 @@ type WTableParams struct {
 	Layout  *WTableLayout  `xml:"w:tblLayout,omitempty"`
 	DocGrid *WIntValue     `xml:"w:docGrid,omitempty"`
 	Look    *WLookValue    `xml:"w:tblLook,omitempty"`
+	Extra   Properties     `xml:"-"`
+}
+
+// marshalXML - кодирование вместе с неизвестными свойствами
+func (wtp WTableParams) marshalXML(encoder *xmlEncoder, start xml.StartElement) error {
+	return encodeProperties(encoder, start, &wtp, &wtp.Extra, tableParamsOrder)
 }
 
 func (tp *TableParams) ToWTableParams() *WTableParams {
@@ -166,6 +213,7 @@ func (tp *TableParams) ToWTableParams() *WTableParams {
 	if tp.Look != nil {
 		wtp.Look = (*WLookValue)(tp.Look)
 	}
+	wtp.Extra = tp.Extra.unchanged(tp)
 	return &wtp
 }
 
@@ -266,12 +314,58 @@ type TableRow struct {
 	Cells       []*TableCell    `xml:"tc,omitempty"`
 	RsidR       string          `xml:"rsidR,attr,omitempty"`
 	RsidTr      string          `xml:"rsidTr,attr,omitempty"`
+	// Attrs - атрибуты строки в исходном порядке (w:rsidTrPr, w14:paraId ...)
+	Attrs []xml.Attr `xml:"-"`
 }
 
 // TableRowParams - row params
 type TableRowParams struct {
-	Height   HeightValue `xml:"trHeight"`
-	IsHeader bool
+	Height   *HeightValue `xml:"trHeight,omitempty"`
+	IsHeader *EmptyValue  `xml:"tblHeader,omitempty"` // if != nil -> isHeader
+	Extra    Properties   `xml:"-"`
+}
+
+// UnmarshalXML - декодирование с сохранением неизвестных свойств
+func (trp *TableRowParams) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	type plain TableRowParams
+	return decodeProperties(decoder, start, (*plain)(trp), &trp.Extra)
+}
+
+// Clone (TableRowParams) - клонирование
+func (trp *TableRowParams) Clone() *TableRowParams {
+	result := new(TableRowParams)
+	if trp.Height != nil {
+		result.Height = new(HeightValue)
+		result.Height.From(trp.Height)
+	}
+	if trp.IsHeader != nil {
+		result.IsHeader = new(EmptyValue)
+	}
+	result.Extra = trp.Extra.Clone()
+	return result
+}
+
+type WTableRowParams struct {
+	Height   *WHeightValue `xml:"w:trHeight,omitempty"`
+	IsHeader *WEmptyValue  `xml:"w:tblHeader,omitempty"`
+	Extra    Properties    `xml:"-"`
+}
+
+// marshalXML - кодирование вместе с неизвестными свойствами
+func (wtrp WTableRowParams) marshalXML(encoder *xmlEncoder, start xml.StartElement) error {
+	return encodeProperties(encoder, start, &wtrp, &wtrp.Extra, tableRowParamsOrder)
+}
+
+func (trp *TableRowParams) toWTableRowParams() *WTableRowParams {
+	wtrp := WTableRowParams{}
+	if trp.Height != nil {
+		wtrp.Height = (*WHeightValue)(trp.Height)
+	}
+	if trp.IsHeader != nil {
+		wtrp.IsHeader = (*WEmptyValue)(trp.IsHeader)
+	}
+	wtrp.Extra = trp.Extra.unchanged(trp)
+	return &wtrp
 }
 
 // TableCell - table cell
@@ -291,6 +385,13 @@ type TableCellParams struct {
 	GridSpan      *IntValue     `xml:"gridSpan,omitempty"`
 	HideMark      *EmptyValue   `xml:"hideMark,omitempty"`
 	NoWrap        *EmptyValue   `xml:"noWrap,omitempty"`
+	Extra         Properties    `xml:"-"`
+}
+
+// UnmarshalXML - декодирование с сохранением неизвестных свойств
+func (tcp *TableCellParams) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	type plain TableCellParams
+	return decodeProperties(decoder, start, (*plain)(tcp), &tcp.Extra)
 }
 
 func (tcp *TableCellParams) toWTableCellParams() *WTableCellParams {
@@ -316,18 +417,22 @@ func (tcp *TableCellParams) toWTableCellParams() *WTableCellParams {
 		wtcp.VerticalAlign = (*WStringValue)(tcp.VerticalAlign)
 	}
 	// VerticalMerge *StringValue  `xml:"vMerge,omitempty"`
+	if tcp.VerticalMerge != nil {
+		wtcp.VerticalMerge = (*WStringValue)(tcp.VerticalMerge)
+	}
+	// GridSpan      *IntValue     `xml:"gridSpan,omitempty"`
 	if tcp.GridSpan != nil {
 		wtcp.GridSpan = (*WIntValue)(tcp.GridSpan)
 	}
-	// GridSpan      *IntValue     `xml:"gridSpan,omitempty"`
+	// HideMark      *EmptyValue   `xml:"hideMark,omitempty"`
 	if tcp.HideMark != nil {
 		wtcp.HideMark = (*WEmptyValue)(tcp.HideMark)
 	}
-	// HideMark      *EmptyValue   `xml:"hideMark,omitempty"`
+	// NoWrap        *EmptyValue   `xml:"noWrap,omitempty"`
 	if tcp.NoWrap != nil {
 		wtcp.NoWrap = (*WEmptyValue)(tcp.NoWrap)
 	}
-	// NoWrap        *EmptyValue   `xml:"noWrap,omitempty"`
+	wtcp.Extra = tcp.Extra.unchanged(tcp)
 	return &wtcp
 }
 
@@ -341,6 +446,12 @@ type WTableCellParams struct {
 	GridSpan      *WIntValue     `xml:"w:gridSpan,omitempty"`
 	HideMark      *WEmptyValue   `xml:"w:hideMark,omitempty"`
 	NoWrap        *WEmptyValue   `xml:"w:noWrap,omitempty"`
+	Extra         Properties     `xml:"-"`
+}
+
+// marshalXML - кодирование вместе с неизвестными свойствами
+func (wtcp WTableCellParams) marshalXML(encoder *xmlEncoder, start xml.StartElement) error {
+	return encodeProperties(encoder, start, &wtcp, &wtcp.Extra, tableCellParamsOrder)
 }
 
 // Clone (TableCell) - клонирование ячейки
@@ -380,6 +491,7 @@ func (cell *TableCell) Clone() *TableCell {
 		result.Params.Borders = new(TableBorders)
 		result.Params.Borders.From(cell.Params.Borders)
 	}
+	result.Params.Extra = cell.Params.Extra.Clone()
 	result.Items = make([]DocItem, 0)
 	for _, item := range cell.Items {
 		if item != nil {
@@ -393,14 +505,10 @@ func (cell *TableCell) Clone() *TableCell {
 func (row *TableRow) Clone() *TableRow {
 	result := new(TableRow)
 	if row.Params != nil {
-		result.Params = new(TableRowParams)
-		result.Params.IsHeader = row.Params.IsHeader
-		result.Params.Height = row.Params.Height
+		result.Params = row.Params.Clone()
 	}
 	if row.OtherParams != nil {
-
-		result.OtherParams = new(TableParamsEx)
-		result.OtherParams.Shadow = row.OtherParams.Shadow
+		result.OtherParams = row.OtherParams.Clone()
 	}
 	// Клонируем ячейки
 	result.Cells = make([]*TableCell, 0)
@@ -411,6 +519,9 @@ func (row *TableRow) Clone() *TableRow {
 	}
 	result.RsidR = row.RsidR
 	result.RsidTr = row.RsidTr
+	if len(row.Attrs) > 0 {
+		result.Attrs = append([]xml.Attr(nil), row.Attrs...)
+	}
 	return result
 }
 
@@ -435,6 +546,7 @@ func (item *TableItem) decode(decoder *xml.Decoder) error {
 						decoder.DecodeElement(&item.Grid, &element)
 					} else if element.Name.Local == "tr" {
 						row := new(TableRow)
+						row.Attrs = element.Copy().Attr
 						for _, attr := range element.Attr {
 							if attr.Name.Local == "rsidR" {
 								row.RsidR = attr.Value
@@ -475,11 +587,9 @@ func (row *TableRow) decode(decoder *xml.Decoder) error {
 			switch element := token.(type) {
 			case xml.StartElement:
 				{
-					if element.Name.Local == "trHeight" {
+					if element.Name.Local == "trPr" {
 						row.Params = new(TableRowParams)
-						decoder.DecodeElement(&row.Params.Height, &element)
-					} else if element.Name.Local == "tblHeader" {
-						row.Params.IsHeader = true
+						decoder.DecodeElement(row.Params, &element)
 					} else if element.Name.Local == "tblPrEx" {
 						row.OtherParams = new(TableParamsEx)
 						decoder.DecodeElement(row.OtherParams, &element)
@@ -540,19 +650,19 @@ func (row *TableCell) decode(decoder *xml.Decoder) error {
 /* КОДИРОВАНИЕ */
 
 // Кодирование таблицы
-func (item *TableItem) encode(encoder *xml.Encoder) error {
+func (item *TableItem) encode(encoder *xmlEncoder) error {
 	if encoder != nil {
 		// Начало таблицы
-		start := xml.StartElement{Name: xml.Name{Local: "w:" + item.Tag()}}
+		start := xml.StartElement{Name: xml.Name{Space: nsW, Local: item.Tag()}}
 		if err := encoder.EncodeToken(start); err != nil {
 			return err
 		}
 		// Параметры таблицы
-		if err := encoder.EncodeElement(item.Params.ToWTableParams(), xml.StartElement{Name: xml.Name{Local: "w:" + "tblPr"}}); err != nil {
+		if err := encoder.EncodeElement(item.Params.ToWTableParams(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "tblPr"}}); err != nil {
 			return err
 		}
 		// Сетка таблицы
-		if err := encoder.EncodeElement(item.Grid.ToWGirdParams(), xml.StartElement{Name: xml.Name{Local: "w:" + "tblGrid"}}); err != nil {
+		if err := encoder.EncodeElement(item.Grid.ToWGirdParams(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "tblGrid"}}); err != nil {
 			return err
 		}
 		// Строки таблицы
@@ -573,15 +683,15 @@ func (item *TableItem) encode(encoder *xml.Encoder) error {
 }
 
 // Кодирование ячейки таблицы
-func (cell *TableCell) encode(encoder *xml.Encoder) error {
+func (cell *TableCell) encode(encoder *xmlEncoder) error {
 	if encoder != nil {
 		// Начало ячейки таблицы
-		start := xml.StartElement{Name: xml.Name{Local: "w:" + "tc"}}
+		start := xml.StartElement{Name: xml.Name{Space: nsW, Local: "tc"}}
 		if err := encoder.EncodeToken(start); err != nil {
 			return err
 		}
 		// Параметры ячейки таблицы
-		if err := encoder.EncodeElement(cell.Params.toWTableCellParams(), xml.StartElement{Name: xml.Name{Local: "w:" + "tcPr"}}); err != nil {
+		if err := encoder.EncodeElement(cell.Params.toWTableCellParams(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "tcPr"}}); err != nil {
 			return err
 		}
 		// Кодируем составные элементы
@@ -600,49 +710,25 @@ func (cell *TableCell) encode(encoder *xml.Encoder) error {
 }
 
 // Кодирование строки таблицы
-func (row *TableRow) encode(encoder *xml.Encoder) error {
+func (row *TableRow) encode(encoder *xmlEncoder) error {
 	if encoder != nil {
 		// Начало строки таблицы
-		var rsidR = xml.Attr{Name: xml.Name{Local: "w:" + "rsidR"}, Value: row.RsidR}
-		var rsidTr = xml.Attr{Name: xml.Name{Local: "w:" + "rsidTr"}, Value: row.RsidTr}
-		var attrs = []xml.Attr{rsidR, rsidTr}
-		start := xml.StartElement{Name: xml.Name{Local: "w:" + "tr"}, Attr: attrs}
+		var rsidR = xml.Attr{Name: xml.Name{Space: nsW, Local: "rsidR"}, Value: row.RsidR}
+		var rsidTr = xml.Attr{Name: xml.Name{Space: nsW, Local: "rsidTr"}, Value: row.RsidTr}
+		start := xml.StartElement{Name: xml.Name{Space: nsW, Local: "tr"}, Attr: itemAttrs(row.Attrs, rsidR, rsidTr)}
 		if err := encoder.EncodeToken(start); err != nil {
 			return err
 		}
 		// Параметры строки таблицы
 		if row.OtherParams != nil {
-			if err := encoder.EncodeElement((*WTableParamsEx)(row.OtherParams), xml.StartElement{Name: xml.Name{Local: "w:" + "tblPrEx"}}); err != nil {
+			if err := encoder.EncodeElement(row.OtherParams.toWTableParamsEx(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "tblPrEx"}}); err != nil {
 				return err
 			}
 		}
-		// Кодируем Параметры
-		startPr := xml.StartElement{Name: xml.Name{Local: "w:" + "trPr"}}
-		if err := encoder.EncodeToken(startPr); err != nil {
-			return err
-		}
 		if row.Params != nil {
-			if err := encoder.EncodeElement(&row.Params.Height, xml.StartElement{Name: xml.Name{Local: "w:" + "trHeight"}}); err != nil {
+			if err := encoder.EncodeElement(row.Params.toWTableRowParams(), xml.StartElement{Name: xml.Name{Space: nsW, Local: "trPr"}}); err != nil {
 				return err
 			}
-			if row.Params.IsHeader {
-				startHeader := xml.StartElement{Name: xml.Name{Local: "w:" + "tblHeader"}}
-				if err := encoder.EncodeToken(startHeader); err != nil {
-					return err
-				}
-				if err := encoder.EncodeToken(startHeader.End()); err != nil {
-					return err
-				}
-				if err := encoder.Flush(); err != nil {
-					return err
-				}
-			}
-		}
-		if err := encoder.EncodeToken(startPr.End()); err != nil {
-			return err
-		}
-		if err := encoder.Flush(); err != nil {
-			return err
 		}
 		// Кодируем ячейки
 		for _, cell := range row.Cells {
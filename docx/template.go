@@ -7,36 +7,64 @@ import (
 	"github.com/kiennh/go-docx-templates/graph"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 var (
 	rxTemplateItem = regexp.MustCompile(`\{\{\s*([\w|\.|$]+)\s*\}\}`)
-	rxMergeCellV   = regexp.MustCompile(`\[\s?v-merge\s?\]`)
-	rxMergeIndex   = regexp.MustCompile(`\[\s?index\s?:\s?[\d|\.|\,|\$]+\s?\]`)
+	rxMergeIndex   = regexp.MustCompile(`\[\s?index\s?:\s?([^\]]*?)\s?\]`)
 	rxBrCellV      = regexp.MustCompile(`\[\s?BR\s?\]`)
+	rxExpandCellH  = regexp.MustCompile(`\[\s?h-expand\s?\]`)
+	rxRowGroup     = regexp.MustCompile(`\[\s?rows\s?:\s?(\d+)\s?\]`)
+	rxExpression   = regexp.MustCompile(`\{\{\{?([^{}]*?)\}?\}\}`)
+	rxMergeCellH   = regexp.MustCompile(`\[\s?h-merge\s?(?::\s?([^\]]*?)\s?)?\]`)
+	rxMergeFlagH   = regexp.MustCompile(`\[\s?h-merge\s?:\s?([\w\.\$]+)\s?\]`)
+	rxNoData       = regexp.MustCompile(`\[\s?no-data\s?\]`)
+
+	// rxExpressionToken - слово выражения: строка в кавычках, помощник, путь к данным или число
+	rxExpressionToken = regexp.MustCompile(`"[^"]*"|'[^']*'|[^\s(){}"'=]+`)
 )
 
+// renderer - параметры рендера части документа
+type renderer struct {
+	emptyArrays EmptyArrayMode
+	directives  map[string]Directive
+	rowHooks    []RowHook
+	cellHooks   []CellHook
+	helpers     map[string]interface{}
+}
+
+// newRenderer (SimpleDocxFile) - параметры рендера из параметров вызова и настроек шаблона
+func (f *SimpleDocxFile) newRenderer(o *renderOptions) *renderer {
+	return &renderer{emptyArrays: o.emptyArrays, directives: mergeDirectives(f.directives),
+		rowHooks: f.rowHooks, cellHooks: f.cellHooks, helpers: mergeHelpers(findLocale(o.locale), f.helpers)}
+}
+
 // Функционал шаблонизатора
-func renderTemplateDocument(document *Document, v interface{}) error {
+func (r *renderer) renderTemplateDocument(document *Document, v interface{}) error {
 	if document != nil {
 		// Проходимся по структуре документа
-		for _, item := range document.Body.Items {
-			if err := renderDocItem(item, v); err != nil {
-				return err
-			}
+		items, err := r.renderItems(document.Body.Items, v)
+		if err != nil {
+			return err
 		}
+		document.Body.Items = items
 		return nil
 	}
 	return errors.New("Not valid template document")
 }
 
-func renderTemplateHeader(header *Header, v interface{}) error {
+func (r *renderer) renderTemplateHeader(header *Header, v interface{}) error {
 	if header != nil {
-		for _, item := range header.Items {
-			if err := renderDocItem(item, v); err != nil {
-				return err
-			}
+		items, err := r.renderItems(header.Items, v)
+		if err != nil {
+			return err
+		}
+		header.Items = items
+		// Колонтитул должен заканчиваться параграфом (сноски и комментарии состоят из контейнеров)
+		if (header.root == "hdr" || header.root == "ftr") && !endsWithParagraph(header.Items) {
+			header.Items = append(header.Items, new(ParagraphItem))
 		}
 		return nil
 	}
@@ -46,17 +74,24 @@ func renderTemplateHeader(header *Header, v interface{}) error {
 // Поиск элементов шаблона и спаивания текстовых элементов
 func findTemplatePatternsInParagraph(p *ParagraphItem) {
 	if p != nil {
-		// Перебор элементов параграфа и поиск начал {{ и конца }}
+		p.Items = findTemplatePatternsInItems(p.Items)
+	}
+}
+
+// findTemplatePatternsInItems - спаивание текстовых элементов одного уровня (параграф, гиперссылка)
+func findTemplatePatternsInItems(items []DocItem) []DocItem {
+	{
+		// Перебор элементов и поиск начал {{ и конца }}
 		var startItem *RecordItem
-		for index := 0; index < len(p.Items); index++ {
-			i := p.Items[index]
+		for index := 0; index < len(items); index++ {
+			i := items[index]
 			if i.Type() == Record {
 				record := i.(*RecordItem)
 				if record != nil {
 					if startItem != nil {
 						startItem.Text.Value += record.Text.Value
 						// Удаляем элемент
-						p.Items = append(p.Items[:index], p.Items[index+1:]...)
+						items = append(items[:index], items[index+1:]...)
 						// Проверка на конец
 						closeIndex := strings.Index(startItem.Text.Value, "}}")
 						if closeIndex < 0 {
@@ -83,37 +118,79 @@ func findTemplatePatternsInParagraph(p *ParagraphItem) {
 			//startItem = nil
 		}
 	}
+	return items
+}
+
+// findTemplatePatternsInDocItem - спаивание шаблонных вставок во всех параграфах элемента
+func findTemplatePatternsInDocItem(item DocItem) {
+	switch elem := item.(type) {
+	case *ParagraphItem:
+		{
+			findTemplatePatternsInParagraph(elem)
+			for _, i := range elem.Items {
+				findTemplatePatternsInDocItem(i)
+			}
+		}
+	case *ContainerItem:
+		{
+			elem.Items = findTemplatePatternsInItems(elem.Items)
+			for _, i := range elem.Items {
+				findTemplatePatternsInDocItem(i)
+			}
+		}
+	case *TableItem:
+		{
+			for _, row := range elem.Rows {
+				if row != nil {
+					for _, cell := range row.Cells {
+						if cell != nil {
+							for _, i := range cell.Items {
+								findTemplatePatternsInDocItem(i)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
 }
 
 // Рендер элемента документа
-func renderDocItem(item DocItem, v interface{}) error {
+func (r *renderer) renderDocItem(item DocItem, v interface{}) error {
 	switch elem := item.(type) {
 	// Параграф
 	case *ParagraphItem:
 		{
 			findTemplatePatternsInParagraph(elem)
 			for _, i := range elem.Items {
-				if err := renderDocItem(i, v); err != nil {
+				if err := r.renderDocItem(i, v); err != nil {
 					return err
 				}
 			}
 		}
+	// Контейнер (сноска, комментарий, гиперссылка)
+	case *ContainerItem:
+		{
+			elem.Items = findTemplatePatternsInItems(elem.Items)
+			items, err := r.renderItems(elem.Items, v)
+			if err != nil {
+				return err
+			}
+			elem.Items = items
+		}
 	// Запись
 	case *RecordItem:
 		{
 			if len(elem.Text.Value) > 0 {
-				if rxTemplateItem.MatchString(elem.Text.Value) {
+				if rxExpression.MatchString(elem.Text.Value) {
 					text := modeTemplateText(elem.Text.Value)
 					switch v.(type) {
 					case *map[string]interface{}:
-						qoute := strings.Index(text, "{{")
-						first_ := strings.Index(text, "_")
-						if first_ > 0 {
-							text = text[:qoute+3] + text[first_+1:]
-						}
+						// Для строки таблицы путь считается от элемента массива: {{{Items_Name}}} -> {{{Name}}}
+						text = stripLinePrefix(text)
 					}
 
-					out, err := raymond.Render(text, v)
+					out, err := r.renderText(text, v)
 					if err != nil {
 						return err
 					}
@@ -124,76 +201,21 @@ func renderDocItem(item DocItem, v interface{}) error {
 	// Таблица
 	case *TableItem:
 		{
-			for rowIndex := 0; rowIndex < len(elem.Rows); rowIndex++ {
-				row := elem.Rows[rowIndex]
-				if row != nil {
-					// Если массив
-					if obj, name, ok := haveArrayInRow(row, v); ok {
-						lines := objToLines(obj, name)
-						template := row.Clone()
-						currentRow := row
-						for _, line := range lines {
-							if currentRow == nil {
-								currentRow = template.Clone()
-								// Insert Row
-								elem.Rows = append(elem.Rows[:rowIndex], append([]*TableRow{currentRow}, elem.Rows[rowIndex:]...)...)
-							}
-							if err := renderRow(currentRow, &line); err != nil {
-								return err
-							}
-							currentRow = nil
-							rowIndex++
-						}
-						template = nil
-						// Балансируем индекс
-						rowIndex--
-						continue
-					}
-					// Если нет
-					if err := renderRow(row, v); err != nil {
-						return err
-					}
-				}
+			if err := expandColumns(elem, v); err != nil {
+				return err
 			}
-			// После обхода таблицы проходимся по ячейкам и проверяем merge флаги
-			// С конца таблицы, проверяем по ячейкам
-			for rowIndex := len(elem.Rows) - 1; rowIndex >= 0; rowIndex-- {
-				setBoldRight := false
-				// Обходим ячейки
-				for cellIndex, cell := range elem.Rows[rowIndex].Cells {
-					if len(cell.Items) > 0 {
-						plainText := plainTextFromTableCell(cell)
-
-						// Если найден флаг соединения
-						if rxMergeCellV.MatchString(plainText) {
-							if rowIndex > 0 {
-								topCell := elem.Rows[rowIndex-1].Cells[cellIndex]
-								if topCell != nil {
-									if plainText == plainTextFromTableCell(topCell) {
-										cell.Params.VerticalMerge = new(StringValue)
-										for _, i := range cell.Items {
-											clearTextFromDocItem(i)
-										}
-										continue
-									}
-								}
-							}
-							cell.Params.VerticalMerge = new(StringValue)
-							cell.Params.VerticalMerge.Value = "restart"
-							removeTemplateFromCell(rxMergeCellV, cell)
-							removeTemplateFromCell(rxMergeIndex, cell)
-						}
-						// Проверка на флаг усановки жирного шрифта во всех ячейках справа
-						if rxBrCellV.MatchString(plainText) {
-							setBoldRight = !setBoldRight
-							removeTemplateFromCell(rxBrCellV, cell)
-						}
-						// Если флаг выставлен, применяем жирный стиль у шрифта
-						if setBoldRight {
-							setBoldToCell(true, cell)
-						}
-					}
-				}
+			rows, err := r.renderRows(elem.Rows, v)
+			if err != nil {
+				return err
+			}
+			elem.Rows = rows
+			// Объединение ячеек по горизонтали
+			for _, row := range elem.Rows {
+				mergeRowCells(row)
+			}
+			// После обхода таблицы выполняем директивы ячеек ([v-merge], [BR], ...)
+			if err := r.applyDirectives(elem); err != nil {
+				return err
 			}
 		}
 	}
@@ -209,6 +231,12 @@ func clearTextFromDocItem(item DocItem) {
 					clearTextFromDocItem(i)
 				}
 			}
+		case *ContainerItem:
+			{
+				for _, i := range elem.Items {
+					clearTextFromDocItem(i)
+				}
+			}
 		case *RecordItem:
 			{
 				elem.Text.Value = ""
@@ -234,16 +262,25 @@ func setBoldToDocItem(bold bool, item DocItem) {
 					setBoldToDocItem(bold, i)
 				}
 			}
+		case *ContainerItem:
+			{
+				for _, i := range elem.Items {
+					setBoldToDocItem(bold, i)
+				}
+			}
 		case *RecordItem:
 			{
 				if bold {
+					if elem.Params == nil {
+						elem.Params = new(RecordParams)
+					}
 					if elem.Params.Bold == nil {
 						elem.Params.Bold = new(EmptyValue)
 					}
 					if elem.Params.BoldCS == nil {
 						elem.Params.BoldCS = new(EmptyValue)
 					}
-				} else {
+				} else if elem.Params != nil {
 					if elem.Params.Bold != nil {
 						elem.Params.Bold = nil
 					}
@@ -275,6 +312,12 @@ func removeTemplateFromDocItem(template *regexp.Regexp, item DocItem) {
 					removeTemplateFromDocItem(template, i)
 				}
 			}
+		case *ContainerItem:
+			{
+				for _, i := range elem.Items {
+					removeTemplateFromDocItem(template, i)
+				}
+			}
 		case *RecordItem:
 			{
 				elem.Text.Value = template.ReplaceAllString(elem.Text.Value, "")
@@ -283,6 +326,73 @@ func removeTemplateFromDocItem(template *regexp.Regexp, item DocItem) {
 	}
 }
 
+// replaceTemplateInDocItem - замена шаблона в тексте элемента документа
+func replaceTemplateInDocItem(template *regexp.Regexp, item DocItem, replace func(string) string) {
+	switch elem := item.(type) {
+	case *ParagraphItem:
+		{
+			for _, i := range elem.Items {
+				replaceTemplateInDocItem(template, i, replace)
+			}
+		}
+	case *ContainerItem:
+		{
+			for _, i := range elem.Items {
+				replaceTemplateInDocItem(template, i, replace)
+			}
+		}
+	case *RecordItem:
+		{
+			elem.Text.Value = template.ReplaceAllStringFunc(elem.Text.Value, replace)
+		}
+	}
+}
+
+// resolveMergeFlag - подстановка значения флага данных в [h-merge:Flag] до рендера ячейки
+func resolveMergeFlag(cell *TableCell, v interface{}) {
+	for _, item := range cell.Items {
+		replaceTemplateInDocItem(rxMergeFlagH, item, func(expr string) string {
+			arg := rxMergeFlagH.FindStringSubmatch(expr)[1]
+			value, ok := lookupValue(v, arg)
+			if !ok {
+				// Значение, указанное в шаблоне ([h-merge:true])
+				return "[h-merge:" + strconv.FormatBool(isTrueText(arg)) + "]"
+			}
+			return "[h-merge:" + strconv.FormatBool(raymond.IsTrue(value)) + "]"
+		})
+	}
+}
+
+// mergeRowCells - объединение ячеек с флагом [h-merge] с соседними справа
+// [h-merge] объединяет ячейки с одинаковым текстом, [h-merge:Flag] - если флаг данных установлен.
+// Строка обходится справа налево, так что цепочка ячеек с флагами объединяется в одну
+func mergeRowCells(row *TableRow) {
+	if row == nil {
+		return
+	}
+	for cellIndex := len(row.Cells) - 1; cellIndex >= 0; cellIndex-- {
+		cell := row.Cells[cellIndex]
+		match := rxMergeCellH.FindStringSubmatch(plainTextFromTableCell(cell))
+		if match == nil {
+			continue
+		}
+		removeTemplateFromCell(rxMergeCellH, cell)
+		if cellIndex+1 >= len(row.Cells) {
+			continue
+		}
+		right := row.Cells[cellIndex+1]
+		merge := false
+		if strings.Contains(match[0], ":") {
+			merge = isTrueText(match[1])
+		} else {
+			merge = strings.TrimSpace(plainTextFromTableCell(cell)) == strings.TrimSpace(rxMergeCellH.ReplaceAllString(plainTextFromTableCell(right), ""))
+		}
+		if merge {
+			mergeCellRight(row, cellIndex)
+		}
+	}
+}
+
 // objToLines - раскладываем объект на строки
 func objToLines(v interface{}, name string) []map[string]interface{} {
 	node := new(graph.Node)
@@ -290,16 +400,234 @@ func objToLines(v interface{}, name string) []map[string]interface{} {
 	return node.ListMap()
 }
 
-// renderRow - вывод строки таблицы
-func renderRow(row *TableRow, v interface{}) error {
+// renderRows - вывод строк таблицы
+// Строки с массивами повторяются по элементам, строки-маркеры раскрывают блоки
+func (r *renderer) renderRows(rows []*TableRow, v interface{}) ([]*TableRow, error) {
+	markers := make([]*blockMarker, len(rows))
+	found := false
+	for index, row := range rows {
+		if marker, ok := rowMarker(row); ok {
+			markers[index] = marker
+			found = true
+		}
+	}
+	if found {
+		return r.renderRowBlocks(rows, markers, v)
+	}
+	result := make([]*TableRow, 0, len(rows))
+	for index := 0; index < len(rows); {
+		if rows[index] == nil {
+			index++
+			continue
+		}
+		// Строка [group-header] перед группой
+		summary := new(summaryRows)
+		if rowHasFlag(rows[index], rxGroupHeader) && index+1 < len(rows) && rows[index+1] != nil {
+			summary.header = rows[index]
+			index++
+		}
+		// Группа строк [rows:N] или одна строка
+		count := rowGroupSize(rows[index])
+		if index+count > len(rows) {
+			count = len(rows) - index
+		}
+		group := rows[index : index+count]
+		index += count
+		// Строка [no-data] сразу после группы
+		var fallback *TableRow
+		if index < len(rows) && rowHasFlag(rows[index], rxNoData) {
+			fallback = rows[index]
+			index++
+		}
+		// Строки [group-footer] и [grand-total] после группы
+		if index < len(rows) && rowHasFlag(rows[index], rxGroupFooter) {
+			summary.footer = rows[index]
+			index++
+		}
+		if index < len(rows) && rowHasFlag(rows[index], rxGrandTotal) {
+			summary.total = rows[index]
+			index++
+		}
+		out, err := r.renderRowGroup(group, fallback, summary, v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, out...)
+	}
+	return result, nil
+}
+
+// rowGroupSize - количество строк в группе, отмеченной флагом [rows:N] в первой строке
+func rowGroupSize(row *TableRow) int {
+	for _, cell := range row.Cells {
+		if match := rxRowGroup.FindStringSubmatch(plainTextFromTableCell(cell)); match != nil {
+			if count, err := strconv.Atoi(match[1]); err == nil && count > 1 {
+				return count
+			}
+		}
+	}
+	return 1
+}
+
+// rowHasFlag - есть ли флаг в ячейках строки
+func rowHasFlag(row *TableRow, flag *regexp.Regexp) bool {
 	if row != nil {
 		for _, cell := range row.Cells {
-			if cell != nil {
-				for _, item := range cell.Items {
-					if err := renderDocItem(item, v); err != nil {
-						return err
+			if flag.MatchString(plainTextFromTableCell(cell)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeTemplateFromRow - очищаем ячейки строки от флага
+func removeTemplateFromRow(template *regexp.Regexp, row *TableRow) {
+	if row != nil {
+		for _, cell := range row.Cells {
+			removeTemplateFromCell(template, cell)
+		}
+	}
+}
+
+// renderRowGroup - вывод группы строк, которая повторяется целиком по элементам массива
+// fallback - строка [no-data], которая выводится вместо группы для пустого массива,
+// summary - строки заголовка и итогов групп [group-by:поле] и общего итога
+func (r *renderer) renderRowGroup(group []*TableRow, fallback *TableRow, summary *summaryRows, v interface{}) ([]*TableRow, error) {
+	removeTemplateFromRow(rxRowGroup, group[0])
+	if fallback != nil {
+		removeTemplateFromRow(rxNoData, fallback)
+	}
+	removeTemplateFromRow(rxGroupHeader, summary.header)
+	removeTemplateFromRow(rxGroupFooter, summary.footer)
+	removeTemplateFromRow(rxGrandTotal, summary.total)
+	field := groupByField(group)
+	selection := newRowsSelection(group)
+	// Массив ищем во всех строках группы
+	for _, row := range group {
+		if obj, name, ok := haveArrayInRow(row, v); ok {
+			array := obj
+			if value, ok := lookupValue(obj, name); ok {
+				array = value
+			}
+			// Строки массива после отбора [filter:] и сортировки [sort-by:]
+			var lines []map[string]interface{}
+			if len(blockElements(array)) > 0 {
+				lines = selection.apply(objToLines(obj, name))
+			}
+			if len(lines) == 0 {
+				result, err := r.renderEmptyArray(group, fallback, name, v)
+				if err == nil && summary.total != nil {
+					// Общий итог пустого массива
+					err = r.renderSummaryRow(summary.total, nil, v)
+					result = append(result, summary.total)
+				}
+				return result, err
+			}
+			result := make([]*TableRow, 0, len(group)*len(lines))
+			for _, span := range groupLines(lines, field) {
+				if summary.header != nil {
+					header := summary.header.Clone()
+					if err := r.renderSummaryRow(header, lines[span[0]:span[1]], &lines[span[0]]); err != nil {
+						return nil, err
+					}
+					result = append(result, header)
+				}
+				for index := span[0]; index < span[1]; index++ {
+					for _, row := range cloneRows(group) {
+						if err := r.renderRow(row, &lines[index]); err != nil {
+							return nil, err
+						}
+						r.callHooks(row, lines[index], index)
+						result = append(result, row)
 					}
 				}
+				if summary.footer != nil {
+					footer := summary.footer.Clone()
+					if err := r.renderSummaryRow(footer, lines[span[0]:span[1]], &lines[span[0]]); err != nil {
+						return nil, err
+					}
+					result = append(result, footer)
+				}
+			}
+			if summary.total != nil {
+				if err := r.renderSummaryRow(summary.total, lines, v); err != nil {
+					return nil, err
+				}
+				result = append(result, summary.total)
+			}
+			return result, nil
+		}
+	}
+	// Если нет, строки итогов выводятся как обычные строки
+	result := make([]*TableRow, 0, len(group)+4)
+	for _, row := range append(append([]*TableRow{summary.header}, group...), fallback, summary.footer, summary.total) {
+		if row != nil {
+			if err := r.renderRow(row, v); err != nil {
+				return nil, err
+			}
+			result = append(result, row)
+		}
+	}
+	return result, nil
+}
+
+// renderEmptyArray - вывод группы строк для пустого массива
+func (r *renderer) renderEmptyArray(group []*TableRow, fallback *TableRow, name string, v interface{}) ([]*TableRow, error) {
+	switch r.emptyArrays {
+	case EmptyArrayError:
+		return nil, errors.New("Empty array " + name + " in table row")
+	case EmptyArrayFallback:
+		if fallback != nil {
+			if err := r.renderRow(fallback, v); err != nil {
+				return nil, err
+			}
+			return []*TableRow{fallback}, nil
+		}
+		// Без строки [no-data] выводится строка шаблона с пустыми значениями
+		line := make(map[string]interface{})
+		for _, row := range group {
+			if err := r.renderRow(row, &line); err != nil {
+				return nil, err
+			}
+		}
+		return group, nil
+	}
+	return nil, nil
+}
+
+// renderRow - вывод строки таблицы
+func (r *renderer) renderRow(row *TableRow, v interface{}) error {
+	if row != nil {
+		return r.renderCells(row.Cells, v)
+	}
+	return nil
+}
+
+// renderCells - вывод ячеек строки таблицы
+func (r *renderer) renderCells(cells []*TableCell, v interface{}) error {
+	markers := make([]*blockMarker, len(cells))
+	found := false
+	for index, cell := range cells {
+		if marker, ok := cellMarker(cell); ok {
+			markers[index] = marker
+			found = true
+		}
+	}
+	if found {
+		return r.renderCellBlocks(cells, markers, v)
+	}
+	for _, cell := range cells {
+		if cell != nil {
+			resolveMergeFlag(cell, v)
+			items, err := r.renderItems(cell.Items, v)
+			if err != nil {
+				return err
+			}
+			cell.Items = items
+			// Ячейка должна заканчиваться параграфом
+			if !endsWithParagraph(cell.Items) {
+				cell.Items = append(cell.Items, new(ParagraphItem))
 			}
 		}
 	}
@@ -307,39 +635,91 @@ func renderRow(row *TableRow, v interface{}) error {
 }
 
 // Модифицируем текст шаблона
+// Значения выводятся без экранирования ({{{Name}}}), блоки ({{#each}}, {{else}}, {{/each}}) остаются как есть
 func modeTemplateText(tpl string) string {
 	//fmt.Println("Mode: ", tpl)
-	tpl = strings.Replace(tpl, "{{", "{{{", -1)
-	tpl = strings.Replace(tpl, "}}", "}}}", -1)
+	tpl = rxExpression.ReplaceAllStringFunc(tpl, func(expr string) string {
+		inner := rxQuotes.ReplaceAllString(strings.TrimSpace(rxExpression.FindStringSubmatch(expr)[1]), `"`)
+		if isBlockExpression(inner) {
+			return "{{" + inner + "}}"
+		}
+		return "{{{" + inner + "}}}"
+	})
 	tpl = strings.Replace(tpl, "$", "_", -1)
 	return strings.Replace(tpl, ":length", "_length", -1)
 }
 
+// expressionPaths - позиции путей к данным в выражении {{...}}: значения, аргументы помощников и параметров
+// Имена помощников (первое слово выражения или подвыражения с аргументами), строки в кавычках
+// и имена параметров (key=) пропускаются
+func expressionPaths(expr string) [][]int {
+	tokens := rxExpressionToken.FindAllStringIndex(expr, -1)
+	result := make([][]int, 0, len(tokens))
+	for _, token := range tokens {
+		before := strings.TrimRight(expr[:token[0]], " \t")
+		switch {
+		case strings.ContainsAny(expr[token[0]:token[0]+1], `"'`):
+		case token[1] < len(expr) && expr[token[1]] == '=':
+		case len(tokens) > 1 && (strings.HasSuffix(before, "(") || len(strings.Trim(before, "{")) == 0):
+		default:
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// stripLinePrefix - пути выражений строки таблицы от элемента массива
+// {{{Items_Name}}} -> {{{Name}}}, {{{currency Items_Amount}}} -> {{{currency Amount}}}
+func stripLinePrefix(text string) string {
+	return rxExpression.ReplaceAllStringFunc(text, func(expr string) string {
+		paths := expressionPaths(expr)
+		for index := len(paths) - 1; index >= 0; index-- {
+			start, end := paths[index][0], paths[index][1]
+			if i := strings.Index(expr[start:end], "_"); i > 0 {
+				expr = expr[:start] + expr[start+i+1:end] + expr[end:]
+			}
+		}
+		return expr
+	})
+}
+
+// isBlockExpression - выражение блока или служебное ({{#if}}, {{/if}}, {{else}}, {{!комментарий}})
+func isBlockExpression(expr string) bool {
+	if expr == "else" || strings.HasPrefix(expr, "else ") {
+		return true
+	}
+	return len(expr) > 0 && strings.ContainsAny(expr[:1], "#/^!>&")
+}
+
+// endsWithParagraph - заканчивается ли список элементов параграфом
+func endsWithParagraph(items []DocItem) bool {
+	return len(items) > 0 && items[len(items)-1].Type() == Paragraph
+}
+
 // haveArrayInRow - содержится ли массив в строке
+// Пути ищутся во всех выражениях ячеек, в том числе в аргументах помощников ({{formatNumber Items$Amount}})
 func haveArrayInRow(row *TableRow, v interface{}) (interface{}, string, bool) {
 	if row != nil {
 		for _, cell := range row.Cells {
-			if match := rxTemplateItem.FindStringSubmatch(plainTextFromTableCell(cell)); match != nil && len(match) > 1 {
-				names := strings.Split(match[1], "$")
-				if len(names) > 0 {
-					t := reflect.TypeOf(v)
-					val := reflect.ValueOf(v)
-					var lastVal reflect.Value
-					for _, name := range names {
-						t := findType(t, name)
-						val, _ := findValue(val, name)
-						if t != nil {
-							if t.Kind() == reflect.Array || t.Kind() == reflect.Slice {
-								if lastVal.IsValid() {
-									return lastVal.Interface(), name, true
-								}
-								return val.Interface(), name, true
-							}
-						} else {
-							break
+			for _, path := range cellPaths(plainTextFromTableCell(cell)) {
+				names := strings.Split(path, "$")
+				t := reflect.TypeOf(v)
+				val := reflect.ValueOf(v)
+				var lastVal reflect.Value
+				for index, name := range names {
+					// Переходим к полю вложенной структуры ({{Doc$Items$Name}})
+					if t = findType(t, name); t == nil {
+						break
+					}
+					field, _ := findValue(val, name)
+					// Массив целиком ({{sum Items "Amount"}}) строку не повторяет
+					if index < len(names)-1 && (t.Kind() == reflect.Array || t.Kind() == reflect.Slice) {
+						if lastVal.IsValid() {
+							return lastVal.Interface(), name, true
 						}
-						lastVal = val
+						return field.Interface(), name, true
 					}
+					val, lastVal = field, field
 				}
 			}
 		}
@@ -347,6 +727,27 @@ func haveArrayInRow(row *TableRow, v interface{}) (interface{}, string, bool) {
 	return nil, "", false
 }
 
+// cellPaths - пути к данным во всех выражениях текста ячейки
+func cellPaths(text string) []string {
+	result := make([]string, 0)
+	for _, match := range rxExpression.FindAllStringSubmatch(text, -1) {
+		for _, path := range expressionPaths(match[1]) {
+			result = append(result, match[1][path[0]:path[1]])
+		}
+	}
+	return result
+}
+
+// mergeKey - ключ объединения ячеек по вертикали
+// Ячейки с флагом [index:ключ] объединяются по ключу группы (например, [index:{{Items$ID}}]),
+// остальные - по совпадению текста
+func mergeKey(text string) string {
+	if match := rxMergeIndex.FindStringSubmatch(text); match != nil {
+		return "[index:" + strings.TrimSpace(match[1]) + "]"
+	}
+	return text
+}
+
 // Простой текс у ячейки
 func plainTextFromTableCell(cell *TableCell) string {
 	var result string
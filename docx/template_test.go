@@ -0,0 +1,705 @@
+package docx
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testLine - строка массива в данных тестов
+type testLine struct {
+	Name string
+	Qty  int
+}
+
+// testReport - данные тестов строк таблицы
+type testReport struct {
+	Title string
+	Items []testLine
+	Empty []testLine
+}
+
+var testRowsData = &testReport{
+	Title: "Report",
+	Items: []testLine{{Name: "x", Qty: 1}, {Name: "y", Qty: 2}},
+	Empty: []testLine{},
+}
+
+func TestEmptyArrays(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		mode EmptyArrayMode
+		want string
+		err  string
+	}{
+		{
+			name: "remove",
+			body: testTable([]string{"Name", "Qty"}, []string{"{{Empty$Name}}", "{{Empty$Qty}}"}),
+			mode: EmptyArrayRemove,
+			want: "Name|Qty",
+		},
+		{
+			name: "remove no-data row",
+			body: testTable([]string{"Name", "Qty"}, []string{"{{Empty$Name}}", "{{Empty$Qty}}"}, []string{"[no-data]Nothing in {{Title}}", ""}),
+			mode: EmptyArrayRemove,
+			want: "Name|Qty",
+		},
+		{
+			name: "fallback",
+			body: testTable([]string{"Name", "Qty"}, []string{"{{Empty$Name}}", "{{Empty$Qty}}"}, []string{"[no-data]Nothing in {{Title}}", ""}),
+			mode: EmptyArrayFallback,
+			want: "Name|Qty\nNothing in Report|",
+		},
+		{
+			name: "fallback without no-data row",
+			body: testTable([]string{"Name", "Qty"}, []string{"{{Empty$Name}}", "{{Empty$Qty}}"}),
+			mode: EmptyArrayFallback,
+			want: "Name|Qty\n|",
+		},
+		{
+			name: "no-data row after filled array",
+			body: testTable([]string{"{{Items$Name}}", "{{Items$Qty}}"}, []string{"[no-data]Nothing", ""}),
+			mode: EmptyArrayFallback,
+			want: "x|1\ny|2",
+		},
+		{
+			name: "error",
+			body: testTable([]string{"{{Empty$Name}}", "{{Empty$Qty}}"}),
+			mode: EmptyArrayError,
+			err:  "Empty array Empty",
+		},
+		{
+			name: "error with filled array",
+			body: testTable([]string{"{{Items$Name}}", "{{Items$Qty}}"}),
+			mode: EmptyArrayError,
+			want: "x|1\ny|2",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, test.body, testRowsData, EmptyArrays(test.mode))
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestHorizontalMerge(t *testing.T) {
+	data := map[string]interface{}{"Total": true, "Off": false, "Label": "Sum"}
+	tests := []struct {
+		name string
+		row  []string
+		want string
+	}{
+		{"equal text", []string{"[h-merge]a", "a", "b"}, "a|b"},
+		{"different text", []string{"[h-merge]a", "c", "b"}, "a|c|b"},
+		{"data flag", []string{"[h-merge:Total]{{Label}}", "", "b"}, "Sum|b"},
+		{"data flag not set", []string{"[h-merge:Off]{{Label}}", "", "b"}, "Sum||b"},
+		{"literal true", []string{"[h-merge:true]{{Label}}", "", "b"}, "Sum|b"},
+		{"literal false", []string{"[h-merge:false]{{Label}}", "", "b"}, "Sum||b"},
+		{"chain", []string{"[h-merge:true]{{Label}}", "[h-merge:true]", "b"}, "Sum"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, testTable(test.row), data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestHelperOnlyRow(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []string
+		want string
+	}{
+		{"helper", []string{"{{qty Items$Qty}}"}, "#1\n#2"},
+		{"helpers in every cell", []string{"{{upper Items$Name}}", "{{qty Items$Qty}}"}, "X|#1\nY|#2"},
+		{"subexpression", []string{"{{upper (qty Items$Qty)}}"}, "#1\n#2"},
+		{"literal before array", []string{`{{upper "no"}}: {{qty Items$Qty}}`}, "NO: #1\nNO: #2"},
+		{"whole array", []string{"{{count Items}}", `{{sum Items "Qty"}}`}, "2|3"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, testTable(test.row))
+			if err := f.RegisterHelper("upper", strings.ToUpper); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.RegisterHelper("qty", func(value int) string { return "#" + strconv.Itoa(value) }); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Render(testRowsData); err != nil {
+				t.Fatal(err)
+			}
+			if result := testText(t, f); result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+// testNestedReport - данные теста массива во вложенной структуре
+type testNestedReport struct {
+	Title string
+	Doc   *testReport
+}
+
+func TestNestedArrayRow(t *testing.T) {
+	data := &testNestedReport{Title: "Nested", Doc: testRowsData}
+	tests := []struct {
+		name string
+		row  []string
+		want string
+	}{
+		{"nested array", []string{"{{Doc$Items$Name}}", "{{Doc$Items$Qty}}"}, "x|1\ny|2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, testTable(test.row), data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+// testFirstTable - первая таблица тела документа
+func testFirstTable(t testing.TB, f *SimpleDocxFile) *TableItem {
+	t.Helper()
+	for _, item := range f.document.Body.Items {
+		if table, ok := item.(*TableItem); ok {
+			return table
+		}
+	}
+	t.Fatal("table not found")
+	return nil
+}
+
+// testVerticalMerge - значения vMerge ячеек столбца column: restart, continue или пустая строка
+func testVerticalMerge(table *TableItem, column int) []string {
+	result := make([]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		merge := ""
+		if cell := cellAtColumn(row, column); cell != nil && cell.Params.VerticalMerge != nil {
+			merge = "continue"
+			if cell.Params.VerticalMerge.Value == "restart" {
+				merge = "restart"
+			}
+		}
+		result = append(result, merge)
+	}
+	return result
+}
+
+func TestRowGroups(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		want string
+	}{
+		{
+			name: "two rows",
+			rows: [][]string{{"[rows:2]{{Items$Name}}", "{{Items$Qty}}"}, {"qty {{Items$Qty}}", ""}, {"end", ""}},
+			want: "x|1\nqty 1|\ny|2\nqty 2|\nend|",
+		},
+		{
+			name: "marker in second cell",
+			rows: [][]string{{"Name", "Qty"}, {"{{Items$Name}}", "[rows:2]{{Items$Qty}}"}, {"-", "-"}},
+			want: "Name|Qty\nx|1\n-|-\ny|2\n-|-",
+		},
+		{
+			name: "separator row",
+			rows: [][]string{{"[rows:3]{{Items$Name}}", "{{Items$Qty}}"}, {"name: {{Items$Name}}", ""}, {"", ""}},
+			want: "x|1\nname: x|\n|\ny|2\nname: y|\n|",
+		},
+		{
+			name: "array in second row",
+			rows: [][]string{{"[rows:2]Item", ""}, {"{{Items$Name}}", "{{Items$Qty}}"}},
+			want: "Item|\nx|1\nItem|\ny|2",
+		},
+		{
+			name: "empty array",
+			rows: [][]string{{"Name", "Qty"}, {"[rows:2]{{Empty$Name}}", "{{Empty$Qty}}"}, {"{{Empty$Qty}}", ""}},
+			want: "Name|Qty",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := testRender(t, testTable(test.rows...), testRowsData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestRowGroupsVerticalMerge(t *testing.T) {
+	f := testOpen(t, testTable([]string{"[rows:2][v-merge]{{Orders$Category}}", "{{Orders$Name}}"},
+		[]string{"[v-merge]{{Orders$Category}}", "{{Orders$Qty}}"}))
+	if err := f.Render(testOrdersData); err != nil {
+		t.Fatal(err)
+	}
+	want := "A|a1\n|2\n|a2\n|1\nB|b1\n|3\nC|c1\n|1\n|c2\n|5"
+	if result := testText(t, f); result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+	merge := testVerticalMerge(testFirstTable(t, f), 0)
+	wantMerge := []string{"restart", "continue", "continue", "continue", "restart", "continue", "restart", "continue", "continue", "continue"}
+	if !reflect.DeepEqual(merge, wantMerge) {
+		t.Errorf("got vMerge %v, want %v", merge, wantMerge)
+	}
+}
+
+// testMonth - элемент массива столбцов
+type testMonth struct {
+	Name string
+	Plan int
+}
+
+// testPlan - данные тестов раскрытия столбцов
+type testPlan struct {
+	Title  string
+	Items  []testLine
+	Months []testMonth
+}
+
+// testGridWidths - ширины столбцов сетки таблицы
+func testGridWidths(table *TableItem) []int64 {
+	result := make([]int64, 0, len(table.Grid.Cols))
+	for _, col := range table.Grid.Cols {
+		result = append(result, col.Value)
+	}
+	return result
+}
+
+// testCellWidths - ширины ячеек (tcW) по строкам таблицы, объединенные ячейки - "ширина/gridSpan"
+func testCellWidths(table *TableItem) []string {
+	result := make([]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		widths := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			width := strconv.FormatInt(cellWidth(cell), 10)
+			if span := cellSpan(cell); span > 1 {
+				width += "/" + strconv.Itoa(span)
+			}
+			widths = append(widths, width)
+		}
+		result = append(result, strings.Join(widths, " "))
+	}
+	return result
+}
+
+func TestColumnExpansion(t *testing.T) {
+	months := []testMonth{{"Jan", 10}, {"Feb", 20}, {"Mar", 30}}
+	// Строка с ячейкой на всю ширину таблицы
+	spanRow := `<w:tr><w:tc><w:tcPr><w:tcW w:w="9000" w:type="dxa"/><w:gridSpan w:val="3"/></w:tcPr>` +
+		testParagraph("{{Title}}") + `</w:tc></w:tr></w:tbl>`
+	body := strings.TrimSuffix(testTable([]string{"Item", "[h-expand]{{Months$Name}}", "Total"},
+		[]string{"{{Items$Name}}", "{{Months$Plan}}", "{{Items$Qty}}"}), "</w:tbl>") + spanRow
+	tests := []struct {
+		name   string
+		months []testMonth
+		want   string
+		grid   []int64
+		cells  []string
+	}{
+		{
+			name:   "three columns",
+			months: months,
+			want:   "Item|Jan|Feb|Mar|Total\nx|10|20|30|1\ny|10|20|30|2\nPlan",
+			grid:   []int64{3000, 1000, 1000, 1000, 3000},
+			cells:  []string{"3000 1000 1000 1000 3000", "3000 1000 1000 1000 3000", "3000 1000 1000 1000 3000", "9000/5"},
+		},
+		{
+			name:   "one column",
+			months: months[:1],
+			want:   "Item|Jan|Total\nx|10|1\ny|10|2\nPlan",
+			grid:   []int64{3000, 3000, 3000},
+			cells:  []string{"3000 3000 3000", "3000 3000 3000", "3000 3000 3000", "9000/3"},
+		},
+		{
+			name:   "empty array",
+			months: nil,
+			want:   "Item|Total\nx|1\ny|2\nPlan",
+			grid:   []int64{6000, 3000},
+			cells:  []string{"6000 3000", "6000 3000", "6000 3000", "9000/2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, body)
+			data := &testPlan{Title: "Plan", Items: testRowsData.Items, Months: test.months}
+			if err := f.Render(data); err != nil {
+				t.Fatal(err)
+			}
+			if result := testText(t, f); result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+			table := testFirstTable(t, f)
+			if grid := testGridWidths(table); !reflect.DeepEqual(grid, test.grid) {
+				t.Errorf("got grid %v, want %v", grid, test.grid)
+			}
+			if cells := testCellWidths(table); !reflect.DeepEqual(cells, test.cells) {
+				t.Errorf("got cells %v, want %v", cells, test.cells)
+			}
+		})
+	}
+}
+
+// testCustomerOrder - заказ с позициями для тестов объединения по ключу группы
+type testCustomerOrder struct {
+	ID       int
+	Customer string
+	Lines    []testLine
+}
+
+func TestVerticalMerge(t *testing.T) {
+	customers := &struct{ Orders []testCustomerOrder }{Orders: []testCustomerOrder{
+		{1, "Ann", []testLine{{Name: "p1"}, {Name: "p2"}}},
+		{2, "Ann", []testLine{{Name: "p3"}}},
+		{3, "Bob", []testLine{{Name: "p4"}}},
+	}}
+	tests := []struct {
+		name  string
+		row   []string
+		data  interface{}
+		want  string
+		merge []string
+	}{
+		{
+			name:  "equal text",
+			row:   []string{"[v-merge]{{Orders$Category}}", "{{Orders$Name}}"},
+			data:  testOrdersData,
+			want:  "A|a1\n|a2\nB|b1\nC|c1\n|c2",
+			merge: []string{"restart", "continue", "restart", "restart", "continue"},
+		},
+		{
+			name:  "index with different text",
+			row:   []string{"[v-merge][index:{{Orders$Category}}]{{Orders$Name}}", "{{Orders$Qty}}"},
+			data:  testOrdersData,
+			want:  "a1|2\n|1\nb1|3\nc1|1\n|5",
+			merge: []string{"restart", "continue", "restart", "restart", "continue"},
+		},
+		{
+			name:  "equal text of different groups",
+			row:   []string{"[v-merge]{{Orders$Customer}}", "{{Orders$Lines$Name}}"},
+			data:  customers,
+			want:  "Ann|p1\n|p2\n|p3\nBob|p4",
+			merge: []string{"restart", "continue", "continue", "restart"},
+		},
+		{
+			name:  "index of different groups",
+			row:   []string{"[v-merge][index:{{Orders$ID}}]{{Orders$Customer}}", "{{Orders$Lines$Name}}"},
+			data:  customers,
+			want:  "Ann|p1\n|p2\nAnn|p3\nBob|p4",
+			merge: []string{"restart", "continue", "restart", "restart"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, testTable(test.row))
+			if err := f.Render(test.data); err != nil {
+				t.Fatal(err)
+			}
+			if result := testText(t, f); result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+			if merge := testVerticalMerge(testFirstTable(t, f), 0); !reflect.DeepEqual(merge, test.merge) {
+				t.Errorf("got vMerge %v, want %v", merge, test.merge)
+			}
+			// vMerge записывается в документ
+			restart := 0
+			for _, merge := range test.merge {
+				if merge == "restart" {
+					restart++
+				}
+			}
+			result := testDocumentXML(t, f)
+			if count := strings.Count(result, `<w:vMerge w:val="restart"/>`); count != restart {
+				t.Errorf("got %d restart vMerge elements, want %d", count, restart)
+			}
+			if count := strings.Count(result, `<w:vMerge/>`); count != len(test.merge)-restart {
+				t.Errorf("got %d continue vMerge elements, want %d", count, len(test.merge)-restart)
+			}
+		})
+	}
+}
+
+// testFlagLine - строка массива с флагами директив
+type testFlagLine struct {
+	Name   string
+	Hide   bool
+	Strong bool
+}
+
+// testBoldCells - жирный шрифт ячеек по строкам таблицы: b - жирный, "-" - обычный
+func testBoldCells(table *TableItem) []string {
+	result := make([]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		line := ""
+		for _, cell := range row.Cells {
+			bold := "-"
+			forEachRecordInCell(cell, func(record *RecordItem) {
+				if record.Params != nil && record.Params.Bold != nil {
+					bold = "b"
+				}
+			})
+			line += bold
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+func TestDirectives(t *testing.T) {
+	data := &struct{ Items []testFlagLine }{Items: []testFlagLine{{"x", false, true}, {"y", true, false}, {"z", false, false}}}
+	tests := []struct {
+		name string
+		rows [][]string
+		want string
+		bold []string
+	}{
+		{"bold", [][]string{{"[bold]a", "b"}}, "a|b", []string{"b-"}},
+		{"bold flag", [][]string{{"[bold:{{Items$Strong}}]{{Items$Name}}", "n"}}, "x|n\ny|n\nz|n", []string{"b-", "--", "--"}},
+		{"BR to the right", [][]string{{"a", "[BR]b", "c"}}, "a|b|c", []string{"-bb"}},
+		{"BR range", [][]string{{"[BR]a", "b", "[BR]c", "d"}}, "a|b|c|d", []string{"bb--"}},
+		{"hide row", [][]string{{"a"}, {"[hide-row]b"}, {"c"}}, "a\nc", []string{"-", "-"}},
+		{"hide row flag", [][]string{{"[hide-row:{{Items$Hide}}]{{Items$Name}}"}}, "x\nz", []string{"-", "-"}},
+		{"hide row false", [][]string{{"[hide-row:false]a"}}, "a", []string{"-"}},
+		{"unknown", [][]string{{"[note]a"}}, "[note]a", []string{"-"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, testTable(test.rows...))
+			if err := f.Render(data); err != nil {
+				t.Fatal(err)
+			}
+			if result := testText(t, f); result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+			if bold := testBoldCells(testFirstTable(t, f)); !reflect.DeepEqual(bold, test.bold) {
+				t.Errorf("got bold %v, want %v", bold, test.bold)
+			}
+		})
+	}
+}
+
+func TestDirectiveRegistry(t *testing.T) {
+	var calls []string
+	RegisterDirective("testMark", func(ctx *DirectiveContext) error {
+		calls = append(calls, "global "+strings.Join(ctx.Args, "+")+" "+strconv.Itoa(ctx.RowIndex)+":"+strconv.Itoa(ctx.CellIndex))
+		return nil
+	})
+	defer func() {
+		directivesMutex.Lock()
+		delete(directives, "testMark")
+		directivesMutex.Unlock()
+	}()
+	body := testTable([]string{"a", "b"}, []string{"c", "[testMark:x, y][bold]d"})
+	tests := []struct {
+		name  string
+		local map[string]Directive
+		want  string
+		calls []string
+		bold  []string
+		err   bool
+	}{
+		{name: "global", want: "a|b\nc|d", calls: []string{"global x+y 1:1"}, bold: []string{"--", "-b"}},
+		{
+			name: "template over global",
+			local: map[string]Directive{"testMark": func(ctx *DirectiveContext) error {
+				calls = append(calls, "template "+ctx.Value)
+				return nil
+			}},
+			want:  "a|b\nc|d",
+			calls: []string{"template x, y"},
+			bold:  []string{"--", "-b"},
+		},
+		{
+			name: "template over built-in",
+			local: map[string]Directive{"bold": func(ctx *DirectiveContext) error {
+				calls = append(calls, "template bold")
+				return nil
+			}},
+			want:  "a|b\nc|d",
+			calls: []string{"global x+y 1:1", "template bold"},
+			bold:  []string{"--", "--"},
+		},
+		{
+			name: "error",
+			local: map[string]Directive{"testMark": func(ctx *DirectiveContext) error {
+				return errors.New("Mark failed")
+			}},
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls = nil
+			f := testOpen(t, body)
+			for name, directive := range test.local {
+				f.RegisterDirective(name, directive)
+			}
+			err := f.Render(testRowsData)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if result := testText(t, f); result != test.want {
+				t.Errorf("got %q, want %q", result, test.want)
+			}
+			if !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("got calls %q, want %q", calls, test.calls)
+			}
+			if bold := testBoldCells(testFirstTable(t, f)); !reflect.DeepEqual(bold, test.bold) {
+				t.Errorf("got bold %v, want %v", bold, test.bold)
+			}
+		})
+	}
+}
+
+// testHookDoc - данные теста ключей обработчиков для массива во вложенной структуре
+type testHookDoc struct {
+	Title string
+	Doc   testHookItems
+}
+
+// testHookItems - вложенная структура с массивом
+type testHookItems struct {
+	Number string
+	Items  []testLine
+}
+
+func TestHookKeys(t *testing.T) {
+	nested := &testHookDoc{Title: "T"}
+	nested.Doc.Number = "N1"
+	nested.Doc.Items = []testLine{{Name: "x", Qty: 1}, {Name: "y", Qty: 2}}
+	tests := []struct {
+		name string
+		row  []string
+		data interface{}
+		key  string
+	}{
+		{"array", []string{"{{Items$Name}}"}, testRowsData, "Name"},
+		{"nested array", []string{"{{Doc$Items$Name}}"}, nested, "Items_Name"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, testTable(test.row))
+			var names []interface{}
+			f.OnRow(func(row *TableRow, item map[string]interface{}, index int) {
+				names = append(names, item[test.key])
+			})
+			if err := f.Render(test.data); err != nil {
+				t.Fatal(err)
+			}
+			if want := []interface{}{"x", "y"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("item[%q] = %v, want %v", test.key, names, want)
+			}
+		})
+	}
+}
+
+func TestHooks(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  [][]string
+		calls []string
+		bold  []string
+	}{
+		{
+			name: "rows and cells",
+			rows: [][]string{{"Name", "Qty"}, {"{{Items$Name}}", "{{Items$Qty}}"}},
+			calls: []string{"first 0 x", "second 0 x", "cell 0:0 x", "cell 0:1 1",
+				"first 1 y", "second 1 y", "cell 1:0 y", "cell 1:1 2"},
+			bold: []string{"--", "bb", "--"},
+		},
+		{
+			name: "row group",
+			rows: [][]string{{"[rows:2]{{Items$Name}}", "{{Items$Qty}}"}, {"-", "-"}},
+			calls: []string{"first 0 x", "second 0 x", "cell 0:0 x", "cell 0:1 1", "first 0 -", "second 0 -", "cell 0:0 -", "cell 0:1 -",
+				"first 1 y", "second 1 y", "cell 1:0 y", "cell 1:1 2", "first 1 -", "second 1 -", "cell 1:0 -", "cell 1:1 -"},
+			bold: []string{"bb", "bb", "--", "--"},
+		},
+		{
+			name:  "sorted rows",
+			rows:  [][]string{{"[sort-by:-Items$Qty]{{Items$Name}}", "{{Items$Qty}}"}},
+			calls: []string{"first 0 y", "second 0 y", "cell 0:0 y", "cell 0:1 2", "first 1 x", "second 1 x", "cell 1:0 x", "cell 1:1 1"},
+			bold:  []string{"--", "bb"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testOpen(t, testTable(test.rows...))
+			var calls []string
+			f.OnRow(func(row *TableRow, item map[string]interface{}, index int) {
+				calls = append(calls, "first "+strconv.Itoa(index)+" "+plainTextFromTableCell(row.Cells[0]))
+			})
+			f.OnRow(func(row *TableRow, item map[string]interface{}, index int) {
+				calls = append(calls, "second "+strconv.Itoa(index)+" "+plainTextFromTableCell(row.Cells[0]))
+				// Стиль строки по данным элемента
+				if item["Name"] == "x" {
+					row.SetBold(true)
+				}
+			})
+			f.OnCell(func(cell *TableCell, item map[string]interface{}, index int, column int) {
+				calls = append(calls, "cell "+strconv.Itoa(index)+":"+strconv.Itoa(column)+" "+plainTextFromTableCell(cell))
+			})
+			if err := f.Render(testRowsData); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("got calls %q, want %q", calls, test.calls)
+			}
+			if bold := testBoldCells(testFirstTable(t, f)); !reflect.DeepEqual(bold, test.bold) {
+				t.Errorf("got bold %v, want %v", bold, test.bold)
+			}
+		})
+	}
+}
+
+func TestStripLinePrefix(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"{{{Items_Name}}}", "{{{Name}}}"},
+		{"{{{Items_Sub_Name}}} {{{Title}}}", "{{{Sub_Name}}} {{{Title}}}"},
+		{"{{{currency Items_Amount}}}", "{{{currency Amount}}}"},
+		{`{{{date Items_Date layout="long"}}}`, `{{{date Date layout="long"}}}`},
+		{`{{{format_value Items_Amount "a_b" key_name=Items_Digits}}}`, `{{{format_value Amount "a_b" key_name=Digits}}}`},
+		{`{{#each (filter Items_Sub "Paid" true)}}`, `{{#each (filter Sub "Paid" true)}}`},
+		{"{{#if Items_Paid}}yes{{/if}}", "{{#if Paid}}yes{{/if}}"},
+	}
+	for _, test := range tests {
+		if result := stripLinePrefix(test.text); result != test.want {
+			t.Errorf("stripLinePrefix(%q) = %q, want %q", test.text, result, test.want)
+		}
+	}
+}
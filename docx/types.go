@@ -6,6 +6,11 @@ type HeightValue struct {
 	HeightRule string `xml:"hRule,attr,omitempty"`
 }
 
+type WHeightValue struct {
+	Value      int64  `xml:"w:val,attr"`
+	HeightRule string `xml:"w:hRule,attr,omitempty"`
+}
+
 // From (HeightValue)
 func (h *HeightValue) From(h1 *HeightValue) {
 	if h1 != nil {
@@ -110,7 +115,7 @@ type ReferenceValue struct {
 }
 
 type WReferenceValue struct {
-	Type string `xml:"r:type,attr"`
+	Type string `xml:"w:type,attr"`
 	ID   string `xml:"r:id,attr"`
 }
 
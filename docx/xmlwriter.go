@@ -0,0 +1,461 @@
+package docx
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// xmlEncoder - запись XML части документа
+// Имена элементов и атрибутов задаются пространствами имен (xml.Name{Space: nsW, Local: "p"}),
+// префиксы берутся из объявлений документа. Пустые элементы записываются самозакрывающимися
+type xmlEncoder struct {
+	writer *bufio.Writer
+	// stack - открытые элементы с их областями объявлений
+	stack []xmlScope
+	// open - открывающий тег последнего элемента еще не закрыт символом '>'
+	open bool
+	// unknown - счетчик префиксов неизвестных пространств имен
+	unknown int
+}
+
+// xmlScope - открытый элемент и объявленные в нем префиксы
+// element, attr - префикс документа по ключу пространства имен (namespaceKey)
+type xmlScope struct {
+	name    string
+	element map[string]string
+	attr    map[string]string
+}
+
+// xmlMarshaler - значение, которое кодирует себя само (блоки свойств с неизвестными элементами)
+type xmlMarshaler interface {
+	marshalXML(encoder *xmlEncoder, start xml.StartElement) error
+}
+
+// newXMLEncoder - запись XML в writer
+func newXMLEncoder(writer io.Writer) *xmlEncoder {
+	return &xmlEncoder{writer: bufio.NewWriter(writer)}
+}
+
+// namespaceKey - ключ пространства имен: префикс известного пространства имен ("w"),
+// префикс без объявления или адрес неизвестного пространства имен
+func namespaceKey(space string) string {
+	if prefix, ok := namespacePrefixes[space]; ok {
+		return prefix
+	}
+	return space
+}
+
+// lookup - префикс документа для ключа пространства имен
+func (e *xmlEncoder) lookup(key string, attr bool) (string, bool) {
+	for index := len(e.stack) - 1; index >= 0; index-- {
+		scope := e.stack[index].element
+		if attr {
+			scope = e.stack[index].attr
+		}
+		if prefix, ok := scope[key]; ok {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// declare - объявление префикса в области элемента
+// Префикс, совпадающий с ключом пространства имен, имеет приоритет
+func (scope *xmlScope) declare(prefix string, url string) {
+	key := namespaceKey(url)
+	if current, ok := scope.element[key]; !ok || len(current) == 0 || prefix == key {
+		scope.element[key] = prefix
+	}
+	if _, ok := scope.attr[key]; len(prefix) > 0 && (!ok || prefix == key) {
+		scope.attr[key] = prefix
+	}
+}
+
+// qualify - имя элемента или атрибута с префиксом документа
+// Недостающие объявления добавляются в decls и в область элемента
+func (e *xmlEncoder) qualify(name xml.Name, attr bool, scope *xmlScope, decls *[]xml.Attr) string {
+	switch {
+	case len(name.Space) == 0:
+		return name.Local
+	case name.Space == "xmlns" || name.Space == "xml":
+		return name.Space + ":" + name.Local
+	}
+	key := namespaceKey(name.Space)
+	if key == "xml" {
+		return "xml:" + name.Local
+	}
+	prefix, ok := e.lookup(key, attr)
+	if !ok {
+		url := name.Space
+		if strings.Contains(key, ":") {
+			prefix = "ns" + strconv.Itoa(e.unknown)
+			e.unknown++
+		} else {
+			// Известное пространство имен без объявления или префикс без объявления
+			prefix = key
+			if !strings.Contains(url, ":") {
+				url, _ = namespaceByPrefix(key)
+			}
+		}
+		if len(url) > 0 {
+			*decls = append(*decls, xml.Attr{Name: xml.Name{Space: "xmlns", Local: prefix}, Value: url})
+			scope.declare(prefix, url)
+		}
+	}
+	if len(prefix) == 0 {
+		return name.Local
+	}
+	return prefix + ":" + name.Local
+}
+
+// closeStart - завершение открывающего тега перед содержимым элемента
+func (e *xmlEncoder) closeStart() {
+	if e.open {
+		e.writer.WriteByte('>')
+		e.open = false
+	}
+}
+
+// EncodeToken (xmlEncoder) - запись токена
+func (e *xmlEncoder) EncodeToken(token xml.Token) error {
+	switch element := token.(type) {
+	case xml.StartElement:
+		{
+			e.closeStart()
+			e.stack = append(e.stack, xmlScope{element: make(map[string]string), attr: make(map[string]string)})
+			scope := &e.stack[len(e.stack)-1]
+			// Объявления элемента действуют на сам элемент
+			for _, attr := range element.Attr {
+				if attr.Name.Space == "xmlns" {
+					scope.declare(attr.Name.Local, attr.Value)
+				} else if len(attr.Name.Space) == 0 && attr.Name.Local == "xmlns" {
+					scope.declare("", attr.Value)
+				}
+			}
+			decls := make([]xml.Attr, 0)
+			scope.name = e.qualify(element.Name, false, scope, &decls)
+			names := make([]string, len(element.Attr))
+			for index, attr := range element.Attr {
+				names[index] = e.qualify(attr.Name, true, scope, &decls)
+			}
+			e.writer.WriteByte('<')
+			e.writer.WriteString(scope.name)
+			// Недостающие объявления - перед атрибутами
+			for _, attr := range decls {
+				e.writeAttr("xmlns:"+attr.Name.Local, attr.Value)
+			}
+			for index, attr := range element.Attr {
+				e.writeAttr(names[index], attr.Value)
+			}
+			e.open = true
+		}
+	case xml.EndElement:
+		{
+			if len(e.stack) == 0 {
+				return errors.New("Not valid end element " + element.Name.Local)
+			}
+			name := e.stack[len(e.stack)-1].name
+			e.stack = e.stack[:len(e.stack)-1]
+			if e.open {
+				e.writer.WriteString("/>")
+				e.open = false
+			} else {
+				e.writer.WriteString("</")
+				e.writer.WriteString(name)
+				e.writer.WriteByte('>')
+			}
+		}
+	case xml.CharData:
+		{
+			if len(element) > 0 {
+				e.closeStart()
+				xml.EscapeText(e.writer, element)
+			}
+		}
+	case xml.Comment:
+		{
+			e.closeStart()
+			e.writer.WriteString("<!--")
+			e.writer.Write(element)
+			e.writer.WriteString("-->")
+		}
+	case xml.ProcInst:
+		{
+			e.closeStart()
+			e.writer.WriteString("<?")
+			e.writer.WriteString(element.Target)
+			if len(element.Inst) > 0 {
+				e.writer.WriteByte(' ')
+				e.writer.Write(element.Inst)
+			}
+			e.writer.WriteString("?>")
+		}
+	case xml.Directive:
+		{
+			e.closeStart()
+			e.writer.WriteString("<!")
+			e.writer.Write(element)
+			e.writer.WriteByte('>')
+		}
+	}
+	return nil
+}
+
+// writeAttr - запись атрибута открывающего тега
+func (e *xmlEncoder) writeAttr(name string, value string) {
+	e.writer.WriteByte(' ')
+	e.writer.WriteString(name)
+	e.writer.WriteString(`="`)
+	xml.EscapeText(e.writer, []byte(value))
+	e.writer.WriteByte('"')
+}
+
+// Flush (xmlEncoder) - запись буфера
+func (e *xmlEncoder) Flush() error {
+	return e.writer.Flush()
+}
+
+/* КОДИРОВАНИЕ СТРУКТУР */
+
+// xmlField - поле структуры по тегу xml
+// Префикс имени в теге ("w:sz") заменяется пространством имен
+type xmlField struct {
+	index     int
+	name      xml.Name
+	attr      bool
+	chardata  bool
+	omitempty bool
+}
+
+var xmlFieldsCache sync.Map
+
+// xmlFieldsOf - кодируемые поля структуры
+func xmlFieldsOf(t reflect.Type) []xmlField {
+	if cached, ok := xmlFieldsCache.Load(t); ok {
+		return cached.([]xmlField)
+	}
+	result := make([]xmlField, 0, t.NumField())
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		tag := field.Tag.Get("xml")
+		if tag == "-" || len(field.PkgPath) > 0 {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		info := xmlField{index: index, name: xml.Name{Local: parts[0]}}
+		if len(parts[0]) == 0 {
+			info.name.Local = field.Name
+		}
+		if i := strings.Index(info.name.Local, ":"); i >= 0 {
+			prefix := info.name.Local[:i]
+			info.name = xml.Name{Space: prefix, Local: info.name.Local[i+1:]}
+			if url, ok := namespaceByPrefix(prefix); ok {
+				info.name.Space = url
+			}
+		}
+		for _, flag := range parts[1:] {
+			switch flag {
+			case "attr":
+				info.attr = true
+			case "chardata":
+				info.chardata = true
+			case "omitempty":
+				info.omitempty = true
+			}
+		}
+		result = append(result, info)
+	}
+	xmlFieldsCache.Store(t, result)
+	return result
+}
+
+// EncodeElement (xmlEncoder) - запись значения элементом start
+// Структуры кодируются по тегам xml полей, пустые указатели пропускаются
+func (e *xmlEncoder) EncodeElement(v interface{}, start xml.StartElement) error {
+	return e.encodeValue(reflect.ValueOf(v), start)
+}
+
+// encodeValue - запись значения элементом start
+func (e *xmlEncoder) encodeValue(val reflect.Value, start xml.StartElement) error {
+	for val.IsValid() && (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil
+	}
+	if marshaler, ok := val.Interface().(xmlMarshaler); ok {
+		return marshaler.marshalXML(e, start)
+	}
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for index := 0; index < val.Len(); index++ {
+			if err := e.encodeValue(val.Index(index), start); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		start.Attr = append(start.Attr, structAttrs(val)...)
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, field := range xmlFieldsOf(val.Type()) {
+			if field.attr {
+				continue
+			}
+			value := val.Field(field.index)
+			if field.omitempty && isEmptyValue(value) {
+				continue
+			}
+			if field.chardata {
+				if err := e.EncodeToken(xml.CharData(valueText(value))); err != nil {
+					return err
+				}
+			} else if err := e.encodeValue(value, xml.StartElement{Name: field.name}); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.EncodeToken(xml.CharData(valueText(val))); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// structAttrs - атрибуты из полей структуры
+func structAttrs(val reflect.Value) []xml.Attr {
+	result := make([]xml.Attr, 0)
+	for _, field := range xmlFieldsOf(val.Type()) {
+		if !field.attr {
+			continue
+		}
+		value := val.Field(field.index)
+		if field.omitempty && isEmptyValue(value) {
+			continue
+		}
+		if (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil() {
+			continue
+		}
+		result = append(result, xml.Attr{Name: field.name, Value: valueText(value)})
+	}
+	return result
+}
+
+// isEmptyValue - значение пропускается при omitempty
+func isEmptyValue(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return val.Len() == 0
+	case reflect.Bool:
+		return !val.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return val.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return val.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return val.IsNil()
+	}
+	return false
+}
+
+// valueText - запись простого значения
+func valueText(val reflect.Value) string {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return ""
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.String:
+		return val.String()
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits())
+	}
+	return ""
+}
+
+// namespaceByPrefix - пространство имен для префикса кодирования
+// Предпочтение отдается переходной (transitional) схеме
+func namespaceByPrefix(prefix string) (string, bool) {
+	candidates := make([]string, 0)
+	for url, p := range namespacePrefixes {
+		if p == prefix {
+			candidates = append(candidates, url)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		si, sj := strings.Contains(candidates[i], "purl.oclc.org"), strings.Contains(candidates[j], "purl.oclc.org")
+		if si != sj {
+			return sj
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0], true
+}
+
+/* КОРЕНЬ ЧАСТИ */
+
+// decodeRootAttrs - разбор атрибутов корня части
+// Объявления пространств имен попадают в scheme, mc:Ignorable - в skip, прочие - в attrs
+func decodeRootAttrs(element *xml.StartElement, scheme map[string]string) (skip string, attrs []xml.Attr) {
+	for _, attr := range element.Attr {
+		if attr.Name.Space == "xmlns" {
+			scheme[attr.Name.Local] = attr.Value
+		} else if len(attr.Name.Space) == 0 && attr.Name.Local == "xmlns" {
+			scheme["xmlns"] = attr.Value
+		} else if attr.Name.Local == "Ignorable" {
+			skip = attr.Value
+		} else {
+			attrs = append(attrs, attr)
+		}
+	}
+	return
+}
+
+// encodeRootAttrs - атрибуты корня части в постоянном порядке
+func encodeRootAttrs(scheme map[string]string, skip string, attrs []xml.Attr) []xml.Attr {
+	result := make([]xml.Attr, 0, len(scheme)+len(attrs)+1)
+	keys := make([]string, 0, len(scheme))
+	for key := range scheme {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == "xmlns" {
+			// Пространство имен по умолчанию - первым
+			result = append([]xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: scheme[key]}}, result...)
+		} else {
+			result = append(result, xml.Attr{Name: xml.Name{Space: "xmlns", Local: key}, Value: scheme[key]})
+		}
+	}
+	if len(skip) > 0 {
+		result = append(result, xml.Attr{Name: xml.Name{Space: nsMC, Local: "Ignorable"}, Value: skip})
+	}
+	return append(result, attrs...)
+}
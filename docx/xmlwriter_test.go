@@ -0,0 +1,70 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritePrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     []string
+	}{
+		{
+			name: "document prefix",
+			document: `<ns0:document xmlns:ns0="` + nsW + `"><ns0:body><ns0:p><ns0:r><ns0:t>a</ns0:t></ns0:r></ns0:p>` +
+				`<ns0:sectPr><ns0:pgSz ns0:w="11900" ns0:h="16840"/></ns0:sectPr></ns0:body></ns0:document>`,
+			want: []string{`<ns0:document xmlns:ns0="` + nsW + `">`, `<ns0:r><ns0:t>a</ns0:t></ns0:r>`, `<ns0:pgSz ns0:w="11900" ns0:h="16840"/>`},
+		},
+		{
+			name: "strict namespace",
+			document: `<w:document xmlns:w="http://purl.oclc.org/ooxml/wordprocessingml/main"><w:body><w:p><w:r><w:t>a</w:t></w:r></w:p>` +
+				`<w:sectPr/></w:body></w:document>`,
+			want: []string{`<w:document xmlns:w="http://purl.oclc.org/ooxml/wordprocessingml/main">`, `<w:r><w:t>a</w:t></w:r>`},
+		},
+		{
+			name: "undeclared prefix",
+			document: `<w:document xmlns:w="` + nsW + `"><w:body><w:p><w:r><w:drawing><wp:inline distT="0">` +
+				`<wp:extent cx="1" cy="2"/></wp:inline></w:drawing></w:r></w:p><w:sectPr/></w:body></w:document>`,
+			want: []string{`<wp:inline xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" distT="0">`},
+		},
+		{
+			name: "drawing",
+			document: `<w:document xmlns:w="` + nsW + `" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+				`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"><w:body><w:p><w:r><w:drawing>` +
+				`<wp:inline><wp:extent cx="1" cy="2"/><a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">` +
+				`<a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">` +
+				`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><pic:blipFill><a:blip r:embed="rId5"/></pic:blipFill></pic:pic>` +
+				`</a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p><w:sectPr/></w:body></w:document>`,
+			want: []string{`<wp:inline><wp:extent cx="1" cy="2"/><a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">`,
+				`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">`, `<a:blip r:embed="rId5"/>`},
+		},
+		{
+			name: "unknown namespace",
+			document: `<w:document xmlns:w="` + nsW + `"><w:body><w:p><x:custom xmlns:x="urn:example" x:a="1"/></w:p>` +
+				`<w:sectPr/></w:body></w:document>`,
+			want: []string{`<x:custom xmlns:x="urn:example" x:a="1"/>`},
+		},
+		{
+			name: "default namespace",
+			document: `<document xmlns="` + nsW + `" xmlns:w="` + nsW + `"><body><p><r><t>a</t></r></p>` +
+				`<sectPr/></body></document>`,
+			want: []string{`<w:r><w:t>a</w:t></w:r>`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := OpenBytes(testZip(t, xmlHeader+test.document))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := testDocumentXML(t, f)
+			for _, want := range test.want {
+				if !strings.Contains(result, want) {
+					t.Errorf("want %s in:\n%s", want, result)
+				}
+			}
+		})
+	}
+}
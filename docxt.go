@@ -3,6 +3,7 @@ package docxt
 import (
 	"errors"
 	"io"
+	"io/fs"
 
 	"github.com/kiennh/go-docx-templates/docx"
 )
@@ -12,15 +13,63 @@ type DocxTemplateFile struct {
 	file *docx.SimpleDocxFile
 }
 
-// OpenTemplate - open template
-func OpenTemplate(fileName string) (*DocxTemplateFile, error) {
-	f, err := docx.OpenFile(fileName)
+// OpenOption - open option
+type OpenOption = docx.OpenOption
+
+// InMemory - load the whole template into memory on open, the source file is not used after that
+func InMemory() OpenOption {
+	return docx.InMemory()
+}
+
+// Deterministic - byte-stable output for the same template and data (fixed zip timestamps)
+func Deterministic() OpenOption {
+	return docx.Deterministic()
+}
+
+// OpenTemplate - open template, the file stays open until Close (unless InMemory is set)
+func OpenTemplate(fileName string, opts ...OpenOption) (*DocxTemplateFile, error) {
+	f, err := docx.OpenFile(fileName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &DocxTemplateFile{file: f}, nil
+}
+
+// OpenTemplateReader - open template from io.ReaderAt
+func OpenTemplateReader(r io.ReaderAt, size int64, opts ...OpenOption) (*DocxTemplateFile, error) {
+	f, err := docx.OpenReader(r, size, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &DocxTemplateFile{file: f}, nil
 }
 
+// OpenTemplateBytes - open template from bytes
+func OpenTemplateBytes(data []byte, opts ...OpenOption) (*DocxTemplateFile, error) {
+	f, err := docx.OpenBytes(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &DocxTemplateFile{file: f}, nil
+}
+
+// OpenTemplateFS - open template from fs.FS (embed.FS, os.DirFS, ...)
+func OpenTemplateFS(fsys fs.FS, name string, opts ...OpenOption) (*DocxTemplateFile, error) {
+	f, err := docx.OpenFS(fsys, name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &DocxTemplateFile{file: f}, nil
+}
+
+// Close (DocxTemplateFile) - close source file
+func (t *DocxTemplateFile) Close() error {
+	if t.file != nil {
+		return t.file.Close()
+	}
+	return errors.New("Not loading template file")
+}
+
 // Save (DocxTemplateFile)
 func (t *DocxTemplateFile) Save(fileName string) error {
 	return t.file.Save(fileName)
@@ -31,18 +80,206 @@ func (t *DocxTemplateFile) Write(w io.Writer) error {
 	return t.file.Write(w)
 }
 
-// RenderTemplate (SimpleDocxFile) - рендер шаблона
-func (t *DocxTemplateFile) RenderTemplate(v interface{}) error {
+// RenderOption - render option
+type RenderOption = docx.RenderOption
+
+// Document part kinds for render options
+const (
+	PartDocument  = docx.PartDocument
+	PartHeader    = docx.PartHeader
+	PartFooter    = docx.PartFooter
+	PartFootnotes = docx.PartFootnotes
+	PartEndnotes  = docx.PartEndnotes
+	PartComments  = docx.PartComments
+)
+
+// SkipPart - do not render parts by name (word/header1.xml) or kind (PartHeader, ...)
+func SkipPart(names ...string) RenderOption {
+	return docx.SkipPart(names...)
+}
+
+// PartData - render parts by name (word/header1.xml) or kind (PartHeader, ...) with other data
+func PartData(name string, v interface{}) RenderOption {
+	return docx.PartData(name, v)
+}
+
+// EmptyArrayMode - output of table rows for an empty array
+type EmptyArrayMode = docx.EmptyArrayMode
+
+// Empty array modes
+const (
+	EmptyArrayRemove   = docx.EmptyArrayRemove
+	EmptyArrayFallback = docx.EmptyArrayFallback
+	EmptyArrayError    = docx.EmptyArrayError
+)
+
+// EmptyArrays - output of table rows for an empty array (EmptyArrayRemove by default)
+func EmptyArrays(mode EmptyArrayMode) RenderOption {
+	return docx.EmptyArrays(mode)
+}
+
+// Locale - language of formatting helpers (formatNumber, currency, date, ...): en (default), ru, vi
+func Locale(name string) RenderOption {
+	return docx.Locale(name)
+}
+
+// Directive - table cell directive [name:args]
+type Directive = docx.Directive
+
+// DirectiveContext - table, row and cell of a directive call
+type DirectiveContext = docx.DirectiveContext
+
+// RegisterDirective - register table cell directive for all templates
+func RegisterDirective(name string, directive Directive) {
+	docx.RegisterDirective(name, directive)
+}
+
+// RegisterDirective (DocxTemplateFile) - register table cell directive for this template
+func (t *DocxTemplateFile) RegisterDirective(name string, directive Directive) {
+	if t.file != nil {
+		t.file.RegisterDirective(name, directive)
+	}
+}
+
+// TableRow - table row
+type TableRow = docx.TableRow
+
+// TableCell - table cell
+type TableCell = docx.TableCell
+
+// RowHook - called after a table row is rendered for an array item
+type RowHook = docx.RowHook
+
+// CellHook - called after a table cell is rendered for an array item
+type CellHook = docx.CellHook
+
+// OnRow (DocxTemplateFile) - add hook called after each table row rendered from an array
+func (t *DocxTemplateFile) OnRow(hook RowHook) {
+	if t.file != nil {
+		t.file.OnRow(hook)
+	}
+}
+
+// OnCell (DocxTemplateFile) - add hook called after each table cell rendered from an array
+func (t *DocxTemplateFile) OnCell(hook CellHook) {
+	if t.file != nil {
+		t.file.OnCell(hook)
+	}
+}
+
+// RegisterHelper (DocxTemplateFile) - register template helper for this template only
+func (t *DocxTemplateFile) RegisterHelper(name string, helper interface{}) error {
 	if t.file != nil {
-		return t.file.Render(v)
+		return t.file.RegisterHelper(name, helper)
 	}
 	return errors.New("Not loading template file")
 }
 
+// RenderTemplate (SimpleDocxFile) - рендер шаблона: тело, колонтитулы, сноски и комментарии
+func (t *DocxTemplateFile) RenderTemplate(v interface{}, opts ...RenderOption) error {
+	if t.file != nil {
+		return t.file.Render(v, opts...)
+	}
+	return errors.New("Not loading template file")
+}
+
+// MergeSeparator - separator of document copies in RenderMerge
+type MergeSeparator = docx.MergeSeparator
+
+// Merge separators
+const (
+	MergePageBreak = docx.MergePageBreak
+	MergeSection   = docx.MergeSection
+)
+
+// SeparateBy - separator of document copies in RenderMerge (MergePageBreak by default)
+func SeparateBy(separator MergeSeparator) RenderOption {
+	return docx.SeparateBy(separator)
+}
+
+// RenderMerge - render document body once per record into one document
+func (t *DocxTemplateFile) RenderMerge(records []interface{}, opts ...RenderOption) error {
+	if t.file != nil {
+		return t.file.RenderMerge(records, opts...)
+	}
+	return errors.New("Not loading template file")
+}
+
+// Template - compiled template, can be executed many times from many goroutines
+type Template struct {
+	template *docx.Template
+}
+
+// Compile - compile template: parsed document is kept in memory and is not changed by Execute
+func (t *DocxTemplateFile) Compile() (*Template, error) {
+	if t.file != nil {
+		c, err := t.file.Compile()
+		if err != nil {
+			return nil, err
+		}
+		return &Template{template: c}, nil
+	}
+	return nil, errors.New("Not loading template file")
+}
+
+// Execute (Template) - render copy of template and write result
+func (t *Template) Execute(v interface{}, w io.Writer, opts ...RenderOption) error {
+	return t.template.Execute(v, w, opts...)
+}
+
+// HeaderType - header/footer type of section
+type HeaderType = docx.HeaderType
+
+// HeaderPart - header/footer part referenced by section
+type HeaderPart = docx.HeaderPart
+
+// Header/footer types
+const (
+	HeaderDefault = docx.HeaderDefault
+	HeaderFirst   = docx.HeaderFirst
+	HeaderEven    = docx.HeaderEven
+)
+
 // RenderHeaderTemplate (SimpleDocxFile) - рендер шаблона
+//
+// Deprecated: use RenderSectionHeaderTemplate
 func (t *DocxTemplateFile) RenderHeaderTemplate(indexHeader int, v interface{}) error {
 	if t.file != nil {
 		return t.file.RenderHeader(indexHeader, v)
 	}
 	return errors.New("Not loading template file")
 }
+
+// RenderFooterTemplate (SimpleDocxFile) - рендер подвала шаблона
+//
+// Deprecated: use RenderSectionFooterTemplate
+func (t *DocxTemplateFile) RenderFooterTemplate(indexFooter int, v interface{}) error {
+	if t.file != nil {
+		return t.file.RenderFooter(indexFooter, v)
+	}
+	return errors.New("Not loading template file")
+}
+
+// RenderSectionHeaderTemplate (SimpleDocxFile) - рендер заголовка секции шаблона
+func (t *DocxTemplateFile) RenderSectionHeaderTemplate(section int, typ HeaderType, v interface{}, opts ...RenderOption) error {
+	if t.file != nil {
+		return t.file.RenderSectionHeader(section, typ, v, opts...)
+	}
+	return errors.New("Not loading template file")
+}
+
+// RenderSectionFooterTemplate (SimpleDocxFile) - рендер подвала секции шаблона
+func (t *DocxTemplateFile) RenderSectionFooterTemplate(section int, typ HeaderType, v interface{}, opts ...RenderOption) error {
+	if t.file != nil {
+		return t.file.RenderSectionFooter(section, typ, v, opts...)
+	}
+	return errors.New("Not loading template file")
+}
+
+// HeaderParts - headers and footers referenced by document sections
+func (t *DocxTemplateFile) HeaderParts() []HeaderPart {
+	if t.file != nil {
+		return t.file.HeaderParts()
+	}
+	return nil
+}
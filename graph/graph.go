@@ -3,6 +3,7 @@ package graph
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 )
 
@@ -115,7 +116,12 @@ func (n *Node) FromObject(obj interface{}) {
 			}
 		}
 	} else if kind == reflect.Map {
-		for _, key := range val.MapKeys() {
+		// Ключи по порядку, чтобы строки не зависели от обхода map
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
 			mapItem := val.MapIndex(key)
 			kind = mapItem.Type().Kind()
 			if kind == reflect.Ptr || kind == reflect.Interface {